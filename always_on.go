@@ -8,6 +8,7 @@ type AlwaysOn struct {
 	Key             string
 	Profile         [8760]float64
 	TotalProduction float64
+	FixedCosts      FixedCosts
 }
 
 // LoadAt returns the load of the dispatchable in frame. May return nil if no
@@ -0,0 +1,126 @@
+package merit
+
+import "math"
+
+// FrameBalance describes where the energy in a calculated frame came from, and
+// where it went.
+//
+// Energy is supplied by always-on producers, flexibles which discharge,
+// dispatchables and imports; demand which is unmet is counted as supply so
+// that the balance closes. Energy is used by consumers, less any demand which
+// was shed, by exports, and by flexibles which charge. Always-on production
+// which could not be used is curtailed.
+type FrameBalance struct {
+	Frame int
+
+	Demand  float64
+	Shed    float64
+	Exports float64
+	Charge  float64
+
+	AlwaysOn     float64
+	Discharge    float64
+	Dispatchable float64
+	Imports      float64
+
+	Curtailed float64
+	Unmet     float64
+
+	PriceSetter Participant
+	Price       float64
+}
+
+// ResidualLoad returns the demand not met by always-on producers.
+func (b FrameBalance) ResidualLoad() float64 {
+	return b.Demand - b.AlwaysOn
+}
+
+// Supply returns the energy supplied in the frame, including unmet demand.
+func (b FrameBalance) Supply() float64 {
+	return b.AlwaysOn + b.Discharge + b.Dispatchable + b.Imports + b.Unmet
+}
+
+// Use returns the energy used in the frame, including curtailed production.
+func (b FrameBalance) Use() float64 {
+	return b.Demand - b.Shed + b.Exports + b.Charge + b.Curtailed
+}
+
+// Imbalance returns the difference between the energy supplied and used. It is
+// zero, or very nearly so, in a correctly calculated frame.
+func (b FrameBalance) Imbalance() float64 {
+	return b.Supply() - b.Use()
+}
+
+// Balanced returns whether the supply and use of energy are equal within the
+// tolerance.
+func (b FrameBalance) Balanced(tolerance float64) bool {
+	return math.Abs(b.Imbalance()) <= tolerance
+}
+
+// BalanceAt returns the energy balance of frame in a calculated order.
+//
+// Energy sent between regions of a Network is not included, so the balance of
+// a region which imports or exports through an interconnector does not close.
+func (o *Order) BalanceAt(frame int) FrameBalance {
+	balance := FrameBalance{
+		Frame:     frame,
+		Demand:    o.DemandAt(frame),
+		Curtailed: o.CurtailedAt(frame),
+		Unmet:     o.UnmetAt(frame),
+		Price:     o.PriceAt(frame),
+	}
+
+	if o.PriceSetters != nil {
+		balance.PriceSetter = o.PriceSetters[frame]
+	}
+
+	for _, consumer := range o.demands() {
+		if c, ok := consumer.(interface{ ShedAt(int) float64 }); ok {
+			balance.Shed += c.ShedAt(frame)
+		}
+	}
+
+	for _, export := range o.Exports {
+		balance.Exports += export.LoadAt(frame)
+	}
+
+	for _, producer := range o.producers() {
+		balance.AlwaysOn += producer.LoadAt(frame)
+	}
+
+	for _, flex := range o.Flexibles {
+		if load := flex.LoadAt(frame); load > 0 {
+			balance.Discharge += load
+		} else {
+			balance.Charge -= load
+		}
+	}
+
+	for _, producer := range o.Dispatchables {
+		balance.Dispatchable += producer.LoadAt(frame)
+	}
+
+	for _, supplier := range o.Suppliers {
+		balance.Dispatchable += supplier.LoadAt(frame)
+	}
+
+	for _, imp := range o.Imports {
+		balance.Imports += imp.LoadAt(frame)
+	}
+
+	return balance
+}
+
+// Unbalanced returns the balance of each frame in a calculated order whose
+// supply and use of energy differ by more than the tolerance.
+func (o *Order) Unbalanced(tolerance float64) []FrameBalance {
+	var frames []FrameBalance
+
+	for frame := 0; frame < 8760; frame++ {
+		if balance := o.BalanceAt(frame); !balance.Balanced(tolerance) {
+			frames = append(frames, balance)
+		}
+	}
+
+	return frames
+}
@@ -0,0 +1,98 @@
+package merit
+
+import "testing"
+
+// balanceOrder returns an order with every kind of participant, whose demand
+// in frame 3 exceeds all supply, and whose always-on production in frame 1
+// exceeds demand and the capacity of the storage.
+func balanceOrder() Order {
+	order := NewOrder()
+
+	order.AddConsumer(&Consumer{
+		Profile:     [8760]float64{2.0, 1.0, 4.0, 20.0, 6.0},
+		TotalDemand: 1.0,
+	})
+
+	order.AddConsumer(&Consumer{
+		Profile:     [8760]float64{1.0, 1.0, 1.0, 1.0, 1.0},
+		TotalDemand: 1.0,
+		Segments:    []DemandSegment{{Share: 1.0, MaxPrice: 2.0}},
+	})
+
+	order.AddAlwaysOn(&AlwaysOn{
+		Profile:         [8760]float64{1.0, 6.0, 0.0, 0.0, 1.0},
+		TotalProduction: 1.0,
+	})
+
+	order.AddStorage(&Storage{
+		Flex:    Flex{Capacity: 1.0, Units: 1.0},
+		reserve: NewReserveWithoutDecay(10.0),
+	})
+
+	order.AddSink(&Sink{Flex: Flex{Capacity: 1.0, Units: 1.0, ChargePrice: 1.5}})
+
+	order.AddDispatchable(&Dispatchable{Cost: 1.0, Capacity: 3.0, Units: 1.0})
+	order.AddDispatchable(&Dispatchable{Cost: 3.0, Capacity: 3.0, Units: 1.0})
+
+	order.AddImport(&Import{
+		Capacity:     1.0,
+		Units:        1.0,
+		Prices:       [8760]float64{2.5, 2.5, 2.5, 2.5, 2.5},
+		Availability: [8760]float64{1.0, 1.0, 1.0, 1.0, 1.0},
+	})
+
+	order.AddExport(&Export{
+		Capacity:     1.0,
+		Units:        1.0,
+		Prices:       [8760]float64{2.0, 2.0, 2.0, 2.0, 2.0},
+		Availability: [8760]float64{1.0, 1.0, 1.0, 1.0, 1.0},
+	})
+
+	return order
+}
+
+func TestBalanceAt(t *testing.T) {
+	order := balanceOrder()
+	Calculate(order)
+
+	if unbalanced := order.Unbalanced(1e-9); len(unbalanced) > 0 {
+		t.Errorf("Unbalanced() = %+v, want none", unbalanced)
+	}
+
+	if unmet := order.BalanceAt(3).Unmet; unmet <= 0 {
+		t.Errorf("BalanceAt(3).Unmet = %f, want more than zero", unmet)
+	}
+
+	if curtailed := order.BalanceAt(1).Curtailed; curtailed <= 0 {
+		t.Errorf("BalanceAt(1).Curtailed = %f, want more than zero", curtailed)
+	}
+}
+
+func TestBalanceAtAfterClearing(t *testing.T) {
+	order := balanceOrder()
+	order.Flexibles = nil
+
+	Clear(order)
+
+	if unbalanced := order.Unbalanced(1e-9); len(unbalanced) > 0 {
+		t.Errorf("Unbalanced() = %+v, want none", unbalanced)
+	}
+}
+
+// Asserts that a frame whose books do not close is flagged.
+func TestUnbalanced(t *testing.T) {
+	order := balanceOrder()
+	Calculate(order)
+
+	order.Dispatchables[0].SetLoadAt(2, order.Dispatchables[0].LoadAt(2)+0.5)
+
+	unbalanced := order.Unbalanced(1e-9)
+
+	if len(unbalanced) != 1 || unbalanced[0].Frame != 2 {
+		t.Fatalf("Unbalanced() = %+v, want only frame 2", unbalanced)
+	}
+
+	if imbalance := unbalanced[0].Imbalance(); imbalance != 0.5 {
+		t.Errorf("Imbalance() = %f, want 0.5", imbalance)
+	}
+}
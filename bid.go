@@ -0,0 +1,162 @@
+package merit
+
+import "sort"
+
+// DemandSegment is a share of the demand of a Consumer which is only met while
+// the price of energy does not exceed MaxPrice. Demand which would cost more is
+// shed instead. Segments are taken in order; once their shares reach the whole
+// demand, any further share is ignored.
+type DemandSegment struct {
+	Share    float64
+	MaxPrice float64
+}
+
+// bid is an amount of price-sensitive demand which is met only while the price
+// does not exceed its price.
+type bid struct {
+	owner  Participant
+	price  float64
+	amount float64
+}
+
+// bidderBidsAt returns the bids of a Bidder in frame.
+func bidderBidsAt(b Bidder, frame int) []bid {
+	var bids []bid
+
+	for _, offered := range b.BidsAt(frame) {
+		bids = append(bids, bid{owner: b, price: offered.Price, amount: offered.Volume})
+	}
+
+	return bids
+}
+
+// chargeBidder is implemented by flexibles which buy energy from dispatchables
+// cheaper than the price they are willing to pay.
+type chargeBidder interface {
+	placeBidAt(frame int) bid
+}
+
+// sheddable is implemented by participants whose demand may be shed when the
+// price of energy exceeds what they are willing to pay.
+type sheddable interface {
+	Participant
+	addShedAt(frame int, amount float64)
+}
+
+// shedDemand records that demand of the owner of a bid was shed in frame.
+func shedDemand(owner Participant, frame int, amount float64) {
+	switch owner := owner.(type) {
+	case sheddable:
+		owner.addShedAt(frame, amount)
+	case Bidder:
+		owner.Shed(frame, amount)
+	}
+}
+
+// bidList is a list of bids sorted by price. Implements sort.Interface.
+type bidList []bid
+
+func (bl bidList) Len() int {
+	return len(bl)
+}
+
+func (bl bidList) Swap(i, j int) {
+	bl[i], bl[j] = bl[j], bl[i]
+}
+
+func (bl bidList) Less(i, j int) bool {
+	return bl[i].price < bl[j].price
+}
+
+// bidsAt returns the price-sensitive demand of every consumer and export in the
+// order in frame, sorted so that bids with the lowest price come first.
+func (o *Order) bidsAt(frame int) bidList {
+	var bids bidList
+
+	for _, consumer := range o.Consumers {
+		bids = append(bids, consumer.bidsAt(frame)...)
+	}
+
+	for _, demand := range o.Demands {
+		if b, ok := demand.(Bidder); ok {
+			bids = append(bids, bidderBidsAt(b, frame)...)
+		}
+	}
+
+	for _, export := range o.Exports {
+		bids = append(bids, export.bidAt(frame))
+	}
+
+	sort.Stable(bids)
+
+	return bids
+}
+
+// chargeBidsAt places the bids of every flexible which buys energy from
+// dispatchables in frame, returning the bids and the total amount bid for.
+func (o *Order) chargeBidsAt(frame int) (bidList, float64) {
+	var bids bidList
+	var sum float64
+
+	for _, flex := range o.chargeList() {
+		if b, ok := flex.(chargeBidder); ok {
+			bid := b.placeBidAt(frame)
+
+			if bid.amount > 0 {
+				bids = append(bids, bid)
+				sum += bid.amount
+			}
+		}
+	}
+
+	return bids, sum
+}
+
+// shedder sheds price-sensitive demand, starting with the bids which are
+// willing to pay the least.
+type shedder struct {
+	bids bidList
+	next int
+
+	// onShed, when set, is called with each bid from which demand is shed,
+	// the amount shed, and the demand which then remains.
+	onShed func(b bid, amount, remaining float64)
+}
+
+// shedBelow sheds demand from each bid whose price is lower than price, never
+// shedding more than remaining. Returns the amount shed and the last bid from
+// which demand was shed.
+func (s *shedder) shedBelow(frame int, price, remaining float64) (float64, bid) {
+	var shed float64
+	var last bid
+
+	for s.next < len(s.bids) && s.bids[s.next].price < price {
+		if remaining-shed <= 0 {
+			break
+		}
+
+		bid := &s.bids[s.next]
+		amount := bid.amount
+
+		if amount > remaining-shed {
+			amount = remaining - shed
+		}
+
+		shedDemand(bid.owner, frame, amount)
+		bid.amount -= amount
+		shed += amount
+		last = *bid
+
+		if s.onShed != nil {
+			s.onShed(*bid, amount, remaining-shed)
+		}
+
+		if bid.amount > 0 {
+			break // Bid is only partially shed, and sets the price.
+		}
+
+		s.next++
+	}
+
+	return shed, last
+}
@@ -1,118 +1,262 @@
 package merit
 
 import (
+	"math"
 	"sort"
 	"sync"
 )
 
 // Calculate receives a merit order and computes which producers are running in
 // each frame, and at what level of production, in order to meet demand.
-func Calculate(order Order) {
-	sort.Sort(order.Dispatchables)
+//
+// An error is returned only when an Observer of the order stops the
+// calculation.
+func Calculate(order Order) error {
+	prepare(&order)
 
 	for frame := 0; frame < 8760; frame++ {
-		calculateFrame(frame, order)
+		if err := calculateFrame(frame, order); err != nil {
+			order.tracker.forget()
+			return err
+		}
 	}
+
+	order.tracker.snapshot(order)
+
+	return nil
 }
 
 // CalculateParallel receives a merit order and computes the batches of frames
 // in goroutines. Not suitable for merit orders which use electricity storage.
-func CalculateParallel(order Order, batches int) {
+//
+// An Observer which stops the calculation stops only its own batch; the first
+// error, in frame order, is returned.
+func CalculateParallel(order Order, batches int) error {
 	var wg sync.WaitGroup
 
+	prepare(&order)
+
 	batchSize := 8760 / batches
+	errs := make([]error, batches)
 
 	for i := 0; i < batches; i++ {
 		wg.Add(1)
 
 		go func(i int) {
-			calculateFrameBatch(batchSize*i, (batchSize * (i + 1)), order)
+			errs[i] = calculateFrameBatch(batchSize*i, (batchSize * (i + 1)), order)
 			wg.Done()
 		}(i)
 	}
 
 	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			order.tracker.forget()
+			return err
+		}
+	}
+
+	order.tracker.snapshot(order)
+
+	return nil
 }
 
-func calculateFrameBatch(start, end int, order Order) {
+// prepare sorts the dispatchables and flexibles in the order, and builds the
+// list of offers from which dispatchable load is assigned. Sorting is stable
+// so that participants with equal costs are always used in the order in which
+// they were added.
+func prepare(order *Order) {
+	sort.Stable(order.Dispatchables)
+
+	order.offers = order.Dispatchables.offers()
+	sort.Stable(order.offers)
+
+	order.charging, order.discharging = sortFlexibles(order.Flexibles)
+}
+
+func calculateFrameBatch(start, end int, order Order) error {
 	for frame := start; frame < end; frame++ {
-		calculateFrame(frame, order)
+		if err := calculateFrame(frame, order); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func calculateFrame(frame int, order Order) {
-	remaining := order.DemandAt(frame)
+func calculateFrame(frame int, order Order) error {
+	return runFrame(order, &FrameState{Frame: frame, observers: order.Observers})
+}
 
-	for _, producer := range order.AlwaysOns {
-		produced := producer.LoadAt(frame)
+// runFrame calculates the frame of the state, and records the results in the
+// order. Returns the first error from an observer.
+func runFrame(order Order, state *FrameState) error {
+	frame := state.Frame
+	state.Remaining = order.DemandAt(frame) + order.exportsAt(frame)
 
-		if produced > remaining {
-			produced -= remaining
-			remaining = 0
+	order.resetAt(frame)
 
-			if produced > 0 {
-				for _, flex := range order.Flexibles {
-					produced -= flex.AssignExcessAt(frame, produced)
+	state.note(Step{Kind: StepDemand, Amount: state.Remaining, Remaining: state.Remaining})
 
-					// If there is no energy remaining to be assigned, we can exit
-					// early and - as an added bonus - prevent assigning tiny
-					// negatives resulting from floating point errors. This would
-					// otherwise mess up technologies which have a Reserve whose
-					// volume is 0.0.
-					if produced <= 0.0 {
-						break
-					}
-				}
-			}
-		} else if produced < remaining {
-			// The producer is providing less energy than remaining demand. Take
-			// it all and continue with the next producer.
-			remaining -= produced
+	for _, stage := range order.stages() {
+		stage.Run(order, state)
+	}
+
+	if state.Remaining > 0 {
+		state.note(Step{Kind: StepUnmet, Amount: state.Remaining, Remaining: state.Remaining})
+	}
+
+	state.note(Step{Kind: StepPrice, Participant: state.PriceSetter, Price: state.Price})
+
+	order.setPriceAt(frame, state.PriceSetter, state.Price)
+	order.setUnmetAt(frame, state.Remaining, state.Excess)
+
+	if !state.observed() {
+		return state.err
+	}
+
+	balance := order.BalanceAt(frame)
+
+	for _, observer := range state.observers {
+		if err := observer.ObserveFrame(balance); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// assignDispatchables assigns load to dispatchables in order of cost until the
+// remaining demand is met. Price-sensitive demand, and the demand of flexibles
+// which buy energy, is shed when the next dispatchable would cost more than
+// the participant is willing to pay.
+func assignDispatchables(order Order, state *FrameState) {
+	frame := state.Frame
+	bids := shedder{bids: order.bidsAt(frame)}
+
+	if state.observed() {
+		bids.onShed = func(b bid, amount, remaining float64) {
+			state.note(Step{
+				Kind:        StepShed,
+				Participant: b.owner,
+				Amount:      amount,
+				Price:       b.price,
+				Remaining:   remaining,
+				Reason:      "price exceeds bid",
+			})
+		}
+	}
 
-		if remaining < 0 {
-			remaining = 0
+	if charging, amount := order.chargeBidsAt(frame); amount > 0 {
+		bids.bids = append(bids.bids, charging...)
+		sort.Stable(bids.bids)
+
+		state.Remaining += amount
+
+		for _, b := range charging {
+			state.note(Step{
+				Kind:        StepChargeBid,
+				Participant: b.owner,
+				Amount:      b.amount,
+				Price:       b.price,
+				Remaining:   state.Remaining,
+			})
 		}
+	} else if state.Remaining <= 0 && state.PriceSetter != nil {
+		// Demand was met, and the price set, by an earlier stage.
+		return
 	}
 
-	for _, producer := range order.Flexibles {
-		maxLoad := producer.AvailableAt(frame)
+	offers := order.offersAt(frame)
+
+	// The most expensive offer from which load was assigned sets the price if
+	// demand cannot be met.
+	var marginal Participant
+	var marginalCost float64
+
+	for i := 0; i < len(offers); {
+		// Offers of equal cost are grouped together when the order shares load
+		// pro-rata; otherwise each offer is a group of its own.
+		group := offers[i:offers.groupEnd(i, order.ProRata)]
+		maxLoad := group.capacity()
 
-		if remaining > 0 && maxLoad < remaining {
-			producer.SetLoadAt(frame, maxLoad)
+		if shed, last := bids.shedBelow(frame, group[0].cost, state.Remaining); shed > 0 {
+			state.Remaining -= shed
+
+			if state.Remaining <= 0 {
+				// Demand which was shed sets the price.
+				state.setPrice(last.owner, last.price)
+				return
+			}
+		}
+
+		if maxLoad > 0 {
+			marginal, marginalCost = group[0].producer, group[0].cost
+		}
+
+		if maxLoad < state.Remaining {
+			for _, block := range group {
+				addLoad(block.producer, frame, block.capacity)
+
+				state.Remaining -= block.capacity
+				state.noteDispatch(block, block.capacity, "at capacity")
+			}
 		} else {
 			// remaining is less than 0 if always-on supply exceeds demand.
-			if remaining > 0 {
-				producer.SetLoadAt(frame, remaining)
-			}
+			if state.Remaining > 0 {
+				share := state.Remaining / maxLoad
+
+				for _, block := range group {
+					addLoad(block.producer, frame, block.capacity*share)
 
-			if len(order.Dispatchables) > 0 {
-				// TODO Add a test! Panics without the above conditional when
-				// there are no dispatchables.
-				order.PriceSetters[frame] = order.Dispatchables[0]
+					state.Remaining -= block.capacity * share
+					state.noteDispatch(block, block.capacity*share, "demand met")
+				}
 			}
 
-			break // All demand is assigned.
+			state.Remaining = 0
+			state.setPrice(group[0].producer, group[0].cost)
+			return // All demand is assigned.
 		}
 
-		remaining -= maxLoad
+		i += len(group)
 	}
 
-	for _, producer := range order.Dispatchables {
-		maxLoad := producer.TotalCapacity()
+	// There is insufficient capacity to meet demand; price-sensitive demand
+	// is shed before any other demand goes unmet.
+	if shed, last := bids.shedBelow(frame, math.Inf(1), state.Remaining); shed > 0 {
+		state.Remaining -= shed
 
-		if maxLoad < remaining {
-			producer.SetLoadAt(frame, maxLoad)
-		} else {
-			// remaining is less than 0 if always-on supply exceeds demand.
-			if remaining > 0 {
-				producer.SetLoadAt(frame, remaining)
+		if state.Remaining <= 0 {
+			// A bid which bought nothing does not set the price; the most
+			// expensive offer used, or an earlier stage, does instead.
+			if last.amount > 0 {
+				state.setPrice(last.owner, last.price)
+			} else if marginal != nil {
+				state.setPrice(marginal, marginalCost)
 			}
 
-			order.PriceSetters[frame] = producer
-			break // All demand is assigned.
+			return
 		}
+	}
 
-		remaining -= maxLoad
+	// Demand met before any offer was needed keeps the price of the stage
+	// which met it.
+	if state.Remaining > 0 {
+		state.setPrice(order.scarcityPrice(marginal, marginalCost))
 	}
 }
+
+// noteDispatch records load assigned to a block of a dispatchable in the trace
+// of the frame.
+func (s *FrameState) noteDispatch(block offer, amount float64, reason string) {
+	s.note(Step{
+		Kind:        StepDispatchable,
+		Participant: block.producer,
+		Amount:      amount,
+		Price:       block.cost,
+		Remaining:   s.Remaining,
+		Reason:      reason,
+	})
+}
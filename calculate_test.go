@@ -62,7 +62,7 @@ func TestCalculateTwoDispatchables(t *testing.T) {
 		frame  int
 		want1  float64
 		want2  float64
-		wantPS *Dispatchable
+		wantPS Participant
 	}{
 		{0, 0.0, 0.4, &d2},
 		{1, 0.0, 0.8, &d2},
@@ -82,12 +82,297 @@ func TestCalculateTwoDispatchables(t *testing.T) {
 		}
 
 		if setter := order.PriceSetters[test.frame]; setter != test.wantPS {
-			t.Errorf("Calculate assigned price setter in frame %d = %f, want %f",
-				test.frame, setter.Cost, test.wantPS.Cost)
+			t.Errorf("Calculate assigned price setter in frame %d = %p, want %p",
+				test.frame, setter, test.wantPS)
 		}
 	}
 }
 
+// Asserts that each block of a dispatchable is ranked separately, with the
+// load and price setter referring to the dispatchable itself.
+func TestCalculateDispatchableBlocks(t *testing.T) {
+	d1 := Dispatchable{
+		Cost:     3.0,
+		Capacity: 1.0,
+		Units:    1.0,
+		Blocks:   &BlockList{{Share: 0.6, Cost: 1.0}},
+	}
+
+	d2 := Dispatchable{Cost: 2.0, Capacity: 1.0, Units: 1.0}
+
+	cons := Consumer{Profile: [8760]float64{0.5, 1.0, 2.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&d1)
+	order.AddDispatchable(&d2)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame     int
+		want1     float64
+		want2     float64
+		wantPS    Participant
+		wantPrice float64
+	}{
+		{0, 0.5, 0.0, &d1, 1.0},
+		{1, 0.6, 0.4, &d2, 2.0},
+		{2, 1.0, 1.0, &d1, 3.0},
+	}
+
+	for _, test := range tests {
+		if load := d1.LoadAt(test.frame); toFixed(load, 10) != test.want1 {
+			t.Errorf("Calculate assigned dispatchable1 load %d = %f, want %f",
+				test.frame, load, test.want1)
+		}
+
+		if load := d2.LoadAt(test.frame); toFixed(load, 10) != test.want2 {
+			t.Errorf("Calculate assigned dispatchable2 load %d = %f, want %f",
+				test.frame, load, test.want2)
+		}
+
+		if setter := order.PriceSetters[test.frame]; setter != test.wantPS {
+			t.Errorf("Calculate assigned price setter in frame %d = %p, want %p",
+				test.frame, setter, test.wantPS)
+		}
+
+		if price := order.PriceAt(test.frame); price != test.wantPrice {
+			t.Errorf("Calculate assigned price in frame %d = %f, want %f",
+				test.frame, price, test.wantPrice)
+		}
+	}
+}
+
+// Asserts that dispatchables with equal costs are used in the order in which
+// they were added.
+func TestCalculateEqualCostsInOrder(t *testing.T) {
+	var disps []*Dispatchable
+
+	cons := Consumer{Profile: [8760]float64{2.5}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+
+	for i := 0; i < 20; i++ {
+		disp := &Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+		disps = append(disps, disp)
+		order.AddDispatchable(disp)
+	}
+
+	Calculate(order)
+
+	for i, disp := range disps {
+		want := 0.0
+
+		switch {
+		case i < 2:
+			want = 1.0
+		case i == 2:
+			want = 0.5
+		}
+
+		if load := disp.LoadAt(0); load != want {
+			t.Errorf("Calculate assigned dispatchable %d load %f, want %f",
+				i, load, want)
+		}
+	}
+
+	if order.PriceSetters[0] != disps[2] {
+		t.Errorf("Calculate assigned the wrong price setter in frame 0")
+	}
+}
+
+// Asserts that dispatchables with equal costs share load in proportion to
+// their capacity when ProRata is enabled.
+func TestCalculateEqualCostsProRata(t *testing.T) {
+	d1 := Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+	d2 := Dispatchable{Cost: 2.0, Capacity: 1.0, Units: 1.0}
+	d3 := Dispatchable{Cost: 2.0, Capacity: 3.0, Units: 1.0}
+
+	cons := Consumer{Profile: [8760]float64{0.5, 3.0, 6.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.ProRata = true
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&d1)
+	order.AddDispatchable(&d2)
+	order.AddDispatchable(&d3)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame               int
+		want1, want2, want3 float64
+		wantPS              Participant
+	}{
+		{0, 0.5, 0.0, 0.0, &d1},
+		{1, 1.0, 0.5, 1.5, &d2},
+		{2, 1.0, 1.0, 3.0, &d2}, // Demand is not met.
+	}
+
+	for _, test := range tests {
+		loads := []float64{d1.LoadAt(test.frame), d2.LoadAt(test.frame),
+			d3.LoadAt(test.frame)}
+
+		for i, want := range []float64{test.want1, test.want2, test.want3} {
+			if toFixed(loads[i], 10) != want {
+				t.Errorf("Calculate assigned dispatchable%d load %d = %f, "+
+					"want %f", i+1, test.frame, loads[i], want)
+			}
+		}
+
+		if setter := order.PriceSetters[test.frame]; setter != test.wantPS {
+			t.Errorf("Calculate assigned the wrong price setter in frame %d",
+				test.frame)
+		}
+	}
+}
+
+// Asserts that price-sensitive demand is shed rather than running
+// dispatchables which cost more than the consumer is willing to pay.
+func TestCalculatePriceSensitiveConsumer(t *testing.T) {
+	cheap := Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+	dear := Dispatchable{Cost: 5.0, Capacity: 1.0, Units: 1.0}
+
+	cons := Consumer{
+		Profile:     [8760]float64{0.5, 1.5, 2.5},
+		TotalDemand: 1.0,
+		Segments:    []DemandSegment{{Share: 0.4, MaxPrice: 3.0}},
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&cheap)
+	order.AddDispatchable(&dear)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame      int
+		wantCheap  float64
+		wantDear   float64
+		wantShed   float64
+		wantPrice  float64
+		wantSetter Participant
+	}{
+		{0, 0.5, 0.0, 0.0, 1.0, &cheap}, // Cheap enough for all demand.
+		{1, 1.0, 0.0, 0.5, 3.0, &cons},  // Segment partially shed.
+		{2, 1.0, 0.5, 1.0, 5.0, &dear},  // Segment fully shed.
+	}
+
+	for _, test := range tests {
+		if load := cheap.LoadAt(test.frame); toFixed(load, 10) != test.wantCheap {
+			t.Errorf("Calculate assigned cheap load %d = %f, want %f",
+				test.frame, load, test.wantCheap)
+		}
+
+		if load := dear.LoadAt(test.frame); toFixed(load, 10) != test.wantDear {
+			t.Errorf("Calculate assigned dear load %d = %f, want %f",
+				test.frame, load, test.wantDear)
+		}
+
+		if shed := cons.ShedAt(test.frame); toFixed(shed, 10) != test.wantShed {
+			t.Errorf("Calculate shed demand %d = %f, want %f",
+				test.frame, shed, test.wantShed)
+		}
+
+		if price := order.PriceAt(test.frame); price != test.wantPrice {
+			t.Errorf("Calculate assigned price in frame %d = %f, want %f",
+				test.frame, price, test.wantPrice)
+		}
+
+		if setter := order.PriceSetters[test.frame]; setter != test.wantSetter {
+			t.Errorf("Calculate assigned the wrong price setter in frame %d",
+				test.frame)
+		}
+	}
+}
+
+// Asserts that demand beyond the capacity of every producer is recorded as
+// unmet.
+func TestCalculateUnmetDemand(t *testing.T) {
+	disp := Dispatchable{Capacity: 0.5, Units: 3.0}
+	cons := Consumer{Profile: [8760]float64{0.2, 1.0}, TotalDemand: 2.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame int
+		want  float64
+	}{
+		{0, 0.0},
+		{1, 0.5},
+		{2, 0.0},
+	}
+
+	for _, test := range tests {
+		if unmet := order.UnmetAt(test.frame); unmet != test.want {
+			t.Errorf("UnmetAt(%d) = %f, want %f", test.frame, unmet, test.want)
+		}
+	}
+}
+
+// Asserts that the most expensive dispatchable sets the price when demand is
+// not met, unless the order has a ScarcityPrice.
+func TestCalculateScarcityPrice(t *testing.T) {
+	cheap := Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+	dear := Dispatchable{Cost: 5.0, Capacity: 1.0, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{3.0}, TotalDemand: 1.0}
+
+	tests := []struct {
+		scarcity   float64
+		wantPrice  float64
+		wantSetter Participant
+	}{
+		{0.0, 5.0, &dear},
+		{100.0, 100.0, nil},
+	}
+
+	for _, test := range tests {
+		order := NewOrder()
+		order.ScarcityPrice = test.scarcity
+		order.AddConsumer(&cons)
+		order.AddDispatchable(&cheap)
+		order.AddDispatchable(&dear)
+
+		Calculate(order)
+
+		if price := order.PriceAt(0); price != test.wantPrice {
+			t.Errorf("ScarcityPrice %f: PriceAt(0) = %f, want %f",
+				test.scarcity, price, test.wantPrice)
+		}
+
+		if setter := order.PriceSetters[0]; setter != test.wantSetter {
+			t.Errorf("ScarcityPrice %f: PriceSetters[0] = %p, want %p",
+				test.scarcity, setter, test.wantSetter)
+		}
+	}
+}
+
+// Asserts that the ScarcityPrice is not used when demand is met without any
+// dispatchables.
+func TestCalculateScarcityPriceDemandMet(t *testing.T) {
+	order := NewOrder()
+	order.ScarcityPrice = 500.0
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0})
+	order.AddAlwaysOn(&AlwaysOn{Profile: [8760]float64{2.0}, TotalProduction: 1.0})
+
+	Calculate(order)
+
+	if price := order.PriceAt(0); price != 0.0 {
+		t.Errorf("PriceAt(0) = %f, want 0.0", price)
+	}
+
+	if unmet := order.UnmetAt(0); unmet != 0.0 {
+		t.Errorf("UnmetAt(0) = %f, want 0.0", unmet)
+	}
+}
+
 // Asserts that the concurrent calculator assigns a load in every frame.
 func TestCalculateParallel(t *testing.T) {
 	seed := time.Now().UTC().UnixNano()
@@ -164,6 +449,25 @@ func TestCalculateOneAOOneDisp(t *testing.T) {
 	}
 }
 
+// Asserts that always-on production exactly matching demand leaves nothing for
+// the dispatchables.
+func TestCalculateAlwaysOnMatchingDemand(t *testing.T) {
+	ao := AlwaysOn{Profile: [8760]float64{1.0}, TotalProduction: 1.0}
+	disp := Dispatchable{Capacity: 1.0, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddAlwaysOn(&ao)
+	order.AddDispatchable(&disp)
+
+	Calculate(order)
+
+	if load := disp.LoadAt(0); load != 0.0 {
+		t.Errorf("Calculate assigned dispatchable load 0 = %f, want 0.0", load)
+	}
+}
+
 func TestCalculateOneStorage(t *testing.T) {
 	st := Storage{
 		Flex: Flex{
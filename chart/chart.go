@@ -0,0 +1,192 @@
+// Package chart renders SVG charts of calculated merit orders: the load of
+// each participant over time, the supply stack of a frame, price duration
+// curves, and the energy stored in storage.
+package chart
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+
+	merit "github.com/antw/merit-go"
+)
+
+const (
+	width  = 800.0
+	height = 400.0
+
+	marginLeft   = 70.0
+	marginRight  = 170.0
+	marginTop    = 40.0
+	marginBottom = 50.0
+)
+
+// palette contains the colours used for each series, in turn.
+var palette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+func colour(i int) string {
+	return palette[i%len(palette)]
+}
+
+// point is a position in data coordinates.
+type point struct {
+	x, y float64
+}
+
+// canvas writes an SVG chart, mapping data coordinates to the plot area. The
+// first error encountered while writing is kept and returned by end.
+type canvas struct {
+	w   io.Writer
+	err error
+
+	xMin, xMax float64
+	yMin, yMax float64
+
+	legend int
+}
+
+// newCanvas starts a chart whose plot area spans the given ranges of data.
+func newCanvas(w io.Writer, title string, xMin, xMax, yMin, yMax float64) *canvas {
+	if xMax <= xMin {
+		xMax = xMin + 1
+	}
+
+	if yMax <= yMin {
+		yMax = yMin + 1
+	}
+
+	c := &canvas{w: w, xMin: xMin, xMax: xMax, yMin: yMin, yMax: yMax}
+
+	c.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" `+
+		`viewBox="0 0 %g %g" font-family="sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+
+	c.printf(`<rect width="%g" height="%g" fill="#ffffff"/>`+"\n", width, height)
+	c.text(width/2, marginTop/2, "middle", title)
+
+	return c
+}
+
+func (c *canvas) printf(format string, args ...interface{}) {
+	if c.err == nil {
+		_, c.err = fmt.Fprintf(c.w, format, args...)
+	}
+}
+
+// x returns the horizontal position of a data value.
+func (c *canvas) x(value float64) float64 {
+	plot := width - marginLeft - marginRight
+	return marginLeft + (value-c.xMin)/(c.xMax-c.xMin)*plot
+}
+
+// y returns the vertical position of a data value.
+func (c *canvas) y(value float64) float64 {
+	plot := height - marginTop - marginBottom
+	return height - marginBottom - (value-c.yMin)/(c.yMax-c.yMin)*plot
+}
+
+func (c *canvas) text(x, y float64, anchor, content string) {
+	c.printf(`<text x="%.2f" y="%.2f" text-anchor="%s">%s</text>`+"\n",
+		x, y, anchor, html.EscapeString(content))
+}
+
+// axes draws the axes of the plot, labelled with their ranges.
+func (c *canvas) axes(xLabel, yLabel string) {
+	left, right := c.x(c.xMin), c.x(c.xMax)
+	bottom, top := c.y(c.yMin), c.y(c.yMax)
+
+	c.printf(`<path d="M%.2f %.2fV%.2fH%.2f" fill="none" stroke="#333333"/>`+"\n",
+		left, top, bottom, right)
+
+	c.text(left, bottom+16, "middle", formatValue(c.xMin))
+	c.text(right, bottom+16, "middle", formatValue(c.xMax))
+	c.text(left-6, bottom, "end", formatValue(c.yMin))
+	c.text(left-6, top+4, "end", formatValue(c.yMax))
+
+	c.text((left+right)/2, height-12, "middle", xLabel)
+	c.printf(`<text x="16" y="%.2f" text-anchor="middle" `+
+		`transform="rotate(-90 16 %.2f)">%s</text>`+"\n",
+		(top+bottom)/2, (top+bottom)/2, html.EscapeString(yLabel))
+}
+
+// polygon draws a filled shape through the points.
+func (c *canvas) polygon(points []point, fill string) {
+	c.printf(`<polygon points="%s" fill="%s" stroke="none"/>`+"\n",
+		c.coordinates(points), fill)
+}
+
+// polyline draws a line through the points.
+func (c *canvas) polyline(points []point, stroke string) {
+	c.printf(`<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n",
+		c.coordinates(points), stroke)
+}
+
+// rect draws a filled rectangle between two corners.
+func (c *canvas) rect(from, to point, fill string) {
+	x1, x2 := c.x(from.x), c.x(to.x)
+	y1, y2 := c.y(from.y), c.y(to.y)
+
+	c.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`+"\n",
+		math.Min(x1, x2), math.Min(y1, y2),
+		math.Abs(x2-x1), math.Abs(y2-y1), fill)
+}
+
+// key adds an entry to the legend beside the plot.
+func (c *canvas) key(label, fill string) {
+	x := width - marginRight + 12
+	y := marginTop + float64(c.legend)*18
+
+	c.printf(`<rect x="%.2f" y="%.2f" width="10" height="10" fill="%s"/>`+"\n",
+		x, y, fill)
+	c.text(x+16, y+9, "start", label)
+
+	c.legend++
+}
+
+func (c *canvas) coordinates(points []point) string {
+	var out []byte
+
+	for i, p := range points {
+		if i > 0 {
+			out = append(out, ' ')
+		}
+
+		out = append(out, fmt.Sprintf("%.2f,%.2f", c.x(p.x), c.y(p.y))...)
+	}
+
+	return string(out)
+}
+
+// end closes the chart, returning the first error encountered while writing.
+func (c *canvas) end() error {
+	c.printf("</svg>\n")
+	return c.err
+}
+
+func formatValue(value float64) string {
+	return fmt.Sprintf("%.4g", value)
+}
+
+// checkRange returns an error if start and end do not describe a range of
+// frames in a year.
+func checkRange(start, end int) error {
+	if start < 0 || end > 8760 || start >= end {
+		return fmt.Errorf("chart: invalid frame range %d to %d", start, end)
+	}
+
+	return nil
+}
+
+// keyOf returns the key of a participant, or a name derived from its position
+// when the participant has no key.
+func keyOf(p merit.Participant, i int) string {
+	if key := merit.KeyOf(p); key != "" {
+		return key
+	}
+
+	return fmt.Sprintf("participant %d", i+1)
+}
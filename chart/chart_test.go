@@ -0,0 +1,126 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	merit "github.com/antw/merit-go"
+)
+
+func testOrder() merit.Order {
+	order := merit.NewOrder()
+
+	order.AddConsumer(&merit.Consumer{
+		Key:         "household",
+		Profile:     [8760]float64{1.0, 2.0, 3.0, 2.0},
+		TotalDemand: 1.0,
+	})
+
+	order.AddAlwaysOn(&merit.AlwaysOn{
+		Key:             "solar",
+		Profile:         [8760]float64{0.5, 1.0, 1.5, 0.5},
+		TotalProduction: 1.0,
+	})
+
+	order.AddDispatchable(&merit.Dispatchable{
+		Key:      "gas <ccgt>",
+		Cost:     2.0,
+		Capacity: 5.0,
+		Units:    1.0,
+	})
+
+	merit.Calculate(order)
+
+	return order
+}
+
+// assertSVG asserts that the output is well-formed SVG containing each of the
+// expected strings.
+func assertSVG(t *testing.T, name string, out []byte, contains ...string) {
+	decoder := xml.NewDecoder(bytes.NewReader(out))
+
+	for {
+		_, err := decoder.Token()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("%s wrote invalid SVG: %s", name, err)
+		}
+	}
+
+	if !bytes.HasPrefix(out, []byte("<svg")) {
+		t.Errorf("%s output does not start with <svg", name)
+	}
+
+	for _, expected := range contains {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("%s output does not contain %q", name, expected)
+		}
+	}
+}
+
+func TestLoadStack(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := LoadStack(&buf, testOrder(), 0, 4); err != nil {
+		t.Fatalf("LoadStack returned an error: %s", err)
+	}
+
+	assertSVG(t, "LoadStack", buf.Bytes(),
+		"<polygon", "<polyline", "solar", "gas &lt;ccgt&gt;", "Demand")
+}
+
+func TestLoadStackInvalidRange(t *testing.T) {
+	tests := []struct {
+		start, end int
+	}{
+		{-1, 5},
+		{5, 5},
+		{0, 8761},
+	}
+
+	for _, test := range tests {
+		if LoadStack(io.Discard, testOrder(), test.start, test.end) == nil {
+			t.Errorf("LoadStack(%d, %d) should return an error",
+				test.start, test.end)
+		}
+	}
+}
+
+func TestSupplyStack(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := SupplyStack(&buf, testOrder(), 2); err != nil {
+		t.Fatalf("SupplyStack returned an error: %s", err)
+	}
+
+	assertSVG(t, "SupplyStack", buf.Bytes(), "<rect", "solar", "Demand")
+}
+
+func TestPriceDuration(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := PriceDuration(&buf, testOrder()); err != nil {
+		t.Fatalf("PriceDuration returned an error: %s", err)
+	}
+
+	assertSVG(t, "PriceDuration", buf.Bytes(), "<polyline", "Price")
+}
+
+func TestStorageLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	storage := &merit.Storage{Flex: merit.Flex{Key: "battery"}}
+
+	if err := StorageLevel(&buf, []*merit.Storage{storage}, 0, 24); err != nil {
+		t.Fatalf("StorageLevel returned an error: %s", err)
+	}
+
+	assertSVG(t, "StorageLevel", buf.Bytes(), "<polyline", "battery")
+}
@@ -0,0 +1,104 @@
+package chart
+
+import (
+	"io"
+
+	merit "github.com/antw/merit-go"
+)
+
+// producers returns every participant in the order which produces energy.
+func producers(order merit.Order) []merit.Participant {
+	var list []merit.Participant
+
+	for _, producer := range order.AlwaysOns {
+		list = append(list, producer)
+	}
+
+	for _, producer := range order.Producers {
+		list = append(list, producer)
+	}
+
+	for _, flex := range order.Flexibles {
+		list = append(list, flex)
+	}
+
+	for _, producer := range order.Dispatchables {
+		list = append(list, producer)
+	}
+
+	for _, supplier := range order.Suppliers {
+		list = append(list, supplier)
+	}
+
+	for _, imp := range order.Imports {
+		list = append(list, imp)
+	}
+
+	return list
+}
+
+// LoadStack writes a stacked area chart of the energy produced by each
+// participant in frames start to end (exclusive), with a line showing demand.
+// Energy absorbed by flexibles is not shown.
+func LoadStack(w io.Writer, order merit.Order, start, end int) error {
+	if err := checkRange(start, end); err != nil {
+		return err
+	}
+
+	participants := producers(order)
+	totals := make([]float64, end-start)
+	demand := make([]point, 0, end-start)
+
+	var yMax float64
+
+	layers := make([][]point, len(participants))
+
+	for i, p := range participants {
+		top := make([]point, 0, 2*(end-start))
+		bottom := make([]point, 0, end-start)
+
+		for frame := start; frame < end; frame++ {
+			offset := frame - start
+			bottom = append(bottom, point{float64(frame), totals[offset]})
+
+			if load := p.LoadAt(frame); load > 0 {
+				totals[offset] += load
+			}
+
+			top = append(top, point{float64(frame), totals[offset]})
+
+			if totals[offset] > yMax {
+				yMax = totals[offset]
+			}
+		}
+
+		for j := len(bottom) - 1; j >= 0; j-- {
+			top = append(top, bottom[j])
+		}
+
+		layers[i] = top
+	}
+
+	for frame := start; frame < end; frame++ {
+		value := order.DemandAt(frame)
+		demand = append(demand, point{float64(frame), value})
+
+		if value > yMax {
+			yMax = value
+		}
+	}
+
+	c := newCanvas(w, "Load per participant", float64(start),
+		float64(end-1), 0, yMax)
+
+	for i, layer := range layers {
+		c.polygon(layer, colour(i))
+		c.key(keyOf(participants[i], i), colour(i))
+	}
+
+	c.polyline(demand, "#000000")
+	c.key("Demand", "#000000")
+	c.axes("Frame", "Load")
+
+	return c.end()
+}
@@ -0,0 +1,36 @@
+package chart
+
+import (
+	"io"
+
+	merit "github.com/antw/merit-go"
+)
+
+// PriceDuration writes a price duration curve: the price in every frame of the
+// year, sorted from highest to lowest.
+func PriceDuration(w io.Writer, order merit.Order) error {
+	prices := order.PriceDurationCurve().Values()
+
+	var yMin, yMax float64
+	points := make([]point, len(prices))
+
+	for i, price := range prices {
+		points[i] = point{float64(i), price}
+
+		if price < yMin {
+			yMin = price
+		}
+
+		if price > yMax {
+			yMax = price
+		}
+	}
+
+	c := newCanvas(w, "Price duration curve", 0, float64(len(prices)-1),
+		yMin, yMax)
+
+	c.polyline(points, colour(0))
+	c.axes("Frames", "Price")
+
+	return c.end()
+}
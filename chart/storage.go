@@ -0,0 +1,40 @@
+package chart
+
+import (
+	"io"
+
+	merit "github.com/antw/merit-go"
+)
+
+// StorageLevel writes a chart of the energy stored in each storage at the end
+// of frames start to end (exclusive).
+func StorageLevel(w io.Writer, storages []*merit.Storage, start, end int) error {
+	if err := checkRange(start, end); err != nil {
+		return err
+	}
+
+	var yMax float64
+	lines := make([][]point, len(storages))
+
+	for i, storage := range storages {
+		for frame := start; frame < end; frame++ {
+			stored := storage.StoredAt(frame)
+			lines[i] = append(lines[i], point{float64(frame), stored})
+
+			if stored > yMax {
+				yMax = stored
+			}
+		}
+	}
+
+	c := newCanvas(w, "Energy stored", float64(start), float64(end-1), 0, yMax)
+
+	for i, line := range lines {
+		c.polyline(line, colour(i))
+		c.key(keyOf(storages[i], i), colour(i))
+	}
+
+	c.axes("Frame", "Stored")
+
+	return c.end()
+}
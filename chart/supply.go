@@ -0,0 +1,53 @@
+package chart
+
+import (
+	"io"
+	"math"
+
+	merit "github.com/antw/merit-go"
+)
+
+// SupplyStack writes a chart of the supply curve of frame, with each step drawn
+// as a bar whose width is its capacity and whose height is its price. A
+// vertical line shows the demand in the frame.
+func SupplyStack(w io.Writer, order merit.Order, frame int) error {
+	if err := checkRange(frame, frame+1); err != nil {
+		return err
+	}
+
+	curve := order.SupplyCurveAt(frame)
+	demand := order.DemandAt(frame)
+
+	var yMin, yMax float64
+	xMax := demand
+
+	if len(curve) > 0 {
+		xMax = math.Max(xMax, curve[len(curve)-1].Cumulative)
+	}
+
+	for _, step := range curve {
+		yMin = math.Min(yMin, step.Price)
+		yMax = math.Max(yMax, step.Price)
+	}
+
+	c := newCanvas(w, "Supply stack", 0, xMax, yMin, yMax)
+
+	for i, step := range curve {
+		if step.Capacity <= 0 {
+			continue
+		}
+
+		c.rect(
+			point{step.Cumulative - step.Capacity, 0},
+			point{step.Cumulative, step.Price},
+			colour(i))
+
+		c.key(keyOf(step.Participant, i), colour(i))
+	}
+
+	c.polyline([]point{{demand, yMin}, {demand, yMax}}, "#000000")
+	c.key("Demand", "#000000")
+	c.axes("Capacity", "Price")
+
+	return c.end()
+}
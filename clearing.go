@@ -0,0 +1,249 @@
+package merit
+
+import (
+	"math"
+	"sort"
+)
+
+// Bid is an amount of energy which a participant is willing to buy or sell in
+// a frame, and the price at which it is willing to do so. Demand which must be
+// met regardless of price is bid at positive infinity.
+type Bid struct {
+	Participant Participant
+	Price       float64
+	Volume      float64
+}
+
+// Acceptance describes how much of a Bid was accepted when clearing a frame.
+type Acceptance struct {
+	Bid
+	Accepted float64
+}
+
+// Clearing is the result of clearing the market in a single frame. Demand
+// contains the bids of consumers, from the highest price to the lowest, and
+// Supply the offers of producers from the lowest price to the highest.
+// PriceSetter is the participant whose bid or offer set the price.
+type Clearing struct {
+	Frame       int
+	Price       float64
+	PriceSetter Participant
+	Volume      float64
+	Demand      []Acceptance
+	Supply      []Acceptance
+}
+
+// Clear receives a merit order and clears each frame as a two-sided market:
+// consumers bid for energy and producers offer it, with the price and volume
+// determined by the intersection of the two curves. Always-on producers offer
+// their production at zero cost.
+//
+// Dispatchable loads, shed demand, and prices are assigned to the order as
+// they would be by Calculate. Sinks bid for energy at their ChargePrice.
+// Flexibles which may also produce energy, such as Flex and Storage, are not
+// cleared: they neither bid nor offer, and their loads are left unchanged.
+func Clear(order Order) []Clearing {
+	prepare(&order)
+	order.tracker.forget()
+
+	clearings := make([]Clearing, 8760)
+
+	for frame := range clearings {
+		clearings[frame] = clearFrame(frame, order)
+	}
+
+	return clearings
+}
+
+func clearFrame(frame int, order Order) Clearing {
+	order.resetAt(frame)
+
+	clearing := Clearing{
+		Frame:  frame,
+		Demand: order.demandBidsAt(frame),
+		Supply: order.supplyBidsAt(frame),
+	}
+
+	var demand, supply int
+
+	for demand < len(clearing.Demand) && supply < len(clearing.Supply) {
+		bid := &clearing.Demand[demand]
+		offer := &clearing.Supply[supply]
+
+		// Bids and offers with nothing left to trade cannot set the price.
+		if bid.Accepted >= bid.Volume {
+			demand++
+			continue
+		}
+
+		if offer.Accepted >= offer.Volume {
+			supply++
+			continue
+		}
+
+		if bid.Price < offer.Price {
+			break
+		}
+
+		amount := math.Min(bid.Volume-bid.Accepted, offer.Volume-offer.Accepted)
+
+		bid.Accepted += amount
+		offer.Accepted += amount
+		clearing.Volume += amount
+
+		// The price is set by the offer unless the last bid was only partially
+		// accepted, in which case the bid was the marginal participant.
+		clearing.Price = offer.Price
+		clearing.PriceSetter = offer.Participant
+
+		if bid.Accepted >= bid.Volume {
+			demand++
+		}
+
+		if offer.Accepted >= offer.Volume {
+			supply++
+		}
+	}
+
+	if demand < len(clearing.Demand) {
+		bid := clearing.Demand[demand]
+
+		if !math.IsInf(bid.Price, 1) {
+			if bid.Accepted > 0 {
+				clearing.Price = bid.Price
+				clearing.PriceSetter = bid.Participant
+			}
+		} else if bid.Accepted < bid.Volume {
+			// Demand which must always be met cannot be.
+			clearing.PriceSetter, clearing.Price = order.scarcityPrice(
+				clearing.PriceSetter, clearing.Price)
+		}
+	}
+
+	order.applyClearing(clearing)
+
+	return clearing
+}
+
+// applyClearing assigns the accepted bids and offers in a cleared frame to the
+// participants in the order.
+func (o *Order) applyClearing(clearing Clearing) {
+	frame := clearing.Frame
+
+	o.setPriceAt(frame, clearing.PriceSetter, clearing.Price)
+
+	var unmet, curtailed float64
+
+	// Always-on production which is not accepted is curtailed.
+	for _, offer := range clearing.Supply {
+		switch offer.Participant.(type) {
+		case supplier, Supplier:
+			addLoad(offer.Participant, frame, offer.Accepted)
+		default:
+			curtailed += offer.Volume - offer.Accepted
+		}
+	}
+
+	for _, bid := range clearing.Demand {
+		// Demand which must always be met is unmet, rather than shed, when
+		// there is insufficient supply.
+		if math.IsInf(bid.Price, 1) {
+			unmet += bid.Volume - bid.Accepted
+		} else {
+			shedDemand(bid.Participant, frame, bid.Volume-bid.Accepted)
+		}
+	}
+
+	o.setUnmetAt(frame, unmet, curtailed)
+}
+
+// demandBidsAt returns the bids of each consumer, export and sink in frame,
+// sorted from the highest price to the lowest. Demand which is not
+// price-sensitive is bid at positive infinity.
+func (o *Order) demandBidsAt(frame int) []Acceptance {
+	var bids []Acceptance
+
+	for _, demand := range o.demands() {
+		inelastic := demand.LoadAt(frame)
+
+		var segments []bid
+
+		switch d := demand.(type) {
+		case *Consumer:
+			segments = d.bidsAt(frame)
+		case Bidder:
+			segments = bidderBidsAt(d, frame)
+		}
+
+		for _, segment := range segments {
+			inelastic -= segment.amount
+
+			bids = append(bids, Acceptance{Bid: Bid{
+				Participant: demand,
+				Price:       segment.price,
+				Volume:      segment.amount,
+			}})
+		}
+
+		bids = append(bids, Acceptance{Bid: Bid{
+			Participant: demand,
+			Price:       math.Inf(1),
+			Volume:      inelastic,
+		}})
+	}
+
+	for _, export := range o.Exports {
+		bids = append(bids, Acceptance{Bid: Bid{
+			Participant: export,
+			Price:       export.Prices[frame],
+			Volume:      export.demandAt(frame),
+		}})
+	}
+
+	// Sinks are assumed to absorb all they bid for; any which is not accepted
+	// is given back when the clearing is applied.
+	for _, flex := range o.chargeList() {
+		if b, ok := flex.(chargeBidder); ok {
+			if bid := b.placeBidAt(frame); bid.amount > 0 {
+				bids = append(bids, Acceptance{Bid: Bid{
+					Participant: bid.owner,
+					Price:       bid.price,
+					Volume:      bid.amount,
+				}})
+			}
+		}
+	}
+
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].Price > bids[j].Price
+	})
+
+	return bids
+}
+
+// supplyBidsAt returns the offers of each producer in frame, sorted from the
+// lowest price to the highest.
+func (o *Order) supplyBidsAt(frame int) []Acceptance {
+	var offers []Acceptance
+
+	for _, producer := range o.producers() {
+		offers = append(offers, Acceptance{Bid: Bid{
+			Participant: producer,
+			Volume:      producer.LoadAt(frame),
+		}})
+	}
+
+	for _, offer := range o.offersAt(frame) {
+		offers = append(offers, Acceptance{Bid: Bid{
+			Participant: offer.producer,
+			Price:       offer.cost,
+			Volume:      offer.capacity,
+		}})
+	}
+
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].Price < offers[j].Price
+	})
+
+	return offers
+}
@@ -0,0 +1,194 @@
+package merit
+
+import "testing"
+
+func TestClear(t *testing.T) {
+	ao := AlwaysOn{Profile: [8760]float64{0.5, 0.5, 0.5}, TotalProduction: 1.0}
+	cheap := Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+	dear := Dispatchable{Cost: 5.0, Capacity: 1.0, Units: 1.0}
+
+	cons := Consumer{
+		Profile:     [8760]float64{0.4, 2.0, 4.0},
+		TotalDemand: 1.0,
+		Segments:    []DemandSegment{{Share: 0.5, MaxPrice: 3.0}},
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddAlwaysOn(&ao)
+	order.AddDispatchable(&dear)
+	order.AddDispatchable(&cheap)
+
+	clearings := Clear(order)
+
+	tests := []struct {
+		frame      int
+		wantPrice  float64
+		wantVolume float64
+		wantCheap  float64
+		wantDear   float64
+		wantShed   float64
+	}{
+		{0, 0.0, 0.4, 0.0, 0.0, 0.0}, // Always-on covers all demand.
+		{1, 3.0, 1.5, 1.0, 0.0, 0.5}, // Demand segment partially accepted.
+		{2, 5.0, 2.0, 1.0, 0.5, 2.0}, // Inelastic demand needs the dear plant.
+	}
+
+	for _, test := range tests {
+		clearing := clearings[test.frame]
+
+		if clearing.Frame != test.frame {
+			t.Errorf("Clear()[%d].Frame = %d", test.frame, clearing.Frame)
+		}
+
+		if clearing.Price != test.wantPrice {
+			t.Errorf("Clear()[%d].Price = %f, want %f",
+				test.frame, clearing.Price, test.wantPrice)
+		}
+
+		if volume := toFixed(clearing.Volume, 10); volume != test.wantVolume {
+			t.Errorf("Clear()[%d].Volume = %f, want %f",
+				test.frame, volume, test.wantVolume)
+		}
+
+		if load := cheap.LoadAt(test.frame); toFixed(load, 10) != test.wantCheap {
+			t.Errorf("Clear assigned cheap load %d = %f, want %f",
+				test.frame, load, test.wantCheap)
+		}
+
+		if load := dear.LoadAt(test.frame); toFixed(load, 10) != test.wantDear {
+			t.Errorf("Clear assigned dear load %d = %f, want %f",
+				test.frame, load, test.wantDear)
+		}
+
+		if shed := cons.ShedAt(test.frame); toFixed(shed, 10) != test.wantShed {
+			t.Errorf("Clear shed demand %d = %f, want %f",
+				test.frame, shed, test.wantShed)
+		}
+
+		if price := order.PriceAt(test.frame); price != test.wantPrice {
+			t.Errorf("Clear assigned price in frame %d = %f, want %f",
+				test.frame, price, test.wantPrice)
+		}
+	}
+}
+
+func TestClearBidSetsPrice(t *testing.T) {
+	disp := Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+	dear := Dispatchable{Cost: 5.0, Capacity: 1.0, Units: 1.0}
+
+	cons := Consumer{
+		Profile:     [8760]float64{2.0},
+		TotalDemand: 1.0,
+		Segments:    []DemandSegment{{Share: 0.75, MaxPrice: 3.0}},
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+	order.AddDispatchable(&dear)
+
+	clearing := Clear(order)[0]
+
+	// 0.5 inelastic and 0.5 of the 1.5 segment are met by disp; the rest of
+	// the segment won't pay for dear, so the consumer sets the price.
+	if clearing.Price != 3.0 {
+		t.Errorf("Clear()[0].Price = %f, want 3.0", clearing.Price)
+	}
+
+	if clearing.Volume != 1.0 {
+		t.Errorf("Clear()[0].Volume = %f, want 1.0", clearing.Volume)
+	}
+
+	if setter := order.PriceSetters[0]; setter != &cons {
+		t.Errorf("Clear assigned the wrong price setter in frame 0")
+	}
+
+	for _, offer := range clearing.Supply {
+		want := 0.0
+
+		if offer.Participant == &disp {
+			want = 1.0
+		}
+
+		if offer.Accepted != want {
+			t.Errorf("Clear()[0] accepted %f of offer at %f, want %f",
+				offer.Accepted, offer.Price, want)
+		}
+	}
+}
+
+// Asserts that the ScarcityPrice is used when inelastic demand is not met.
+func TestClearScarcityPrice(t *testing.T) {
+	disp := Dispatchable{Cost: 2.0, Capacity: 1.0, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{3.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.ScarcityPrice = 100.0
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+
+	clearing := Clear(order)[0]
+
+	if clearing.Price != 100.0 || clearing.PriceSetter != nil {
+		t.Errorf("Clear()[0] priced at %f, want the scarcity price of 100",
+			clearing.Price)
+	}
+
+	if unmet := order.UnmetAt(0); unmet != 2.0 {
+		t.Errorf("UnmetAt(0) = %f, want 2.0", unmet)
+	}
+}
+
+// Asserts that segments whose shares exceed the whole demand are limited to it,
+// rather than leaving negative inelastic demand.
+func TestClearExcessSegments(t *testing.T) {
+	disp := Dispatchable{Cost: 10.0, Capacity: 5.0, Units: 1.0}
+
+	cons := Consumer{
+		Profile:     [8760]float64{2.0},
+		TotalDemand: 1.0,
+		Segments: []DemandSegment{
+			{Share: 0.8, MaxPrice: 3.0},
+			{Share: 0.5, MaxPrice: 2.0},
+		},
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+
+	clearing := Clear(order)[0]
+
+	for _, bid := range clearing.Demand {
+		if bid.Volume < 0 {
+			t.Errorf("Clear()[0] has a bid of %f at %f, want no negative bids",
+				bid.Volume, bid.Price)
+		}
+	}
+
+	if shed := toFixed(cons.ShedAt(0), 10); shed != 2.0 {
+		t.Errorf("ShedAt(0) = %f, want 2.0", shed)
+	}
+
+	if load := disp.LoadAt(0); load != 0.0 {
+		t.Errorf("Clear assigned dispatchable load %f, want 0.0", load)
+	}
+}
+
+// Asserts that an offer with nothing to sell does not set the price.
+func TestClearEmptyOffer(t *testing.T) {
+	disp := Dispatchable{Cost: 10.0, Capacity: 1.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{2.0}, TotalDemand: 1.0})
+	order.AddDispatchable(&disp)
+	order.AddImport(&Import{Capacity: 1.0, Units: 1.0, Prices: [8760]float64{50.0}})
+
+	clearing := Clear(order)[0]
+
+	if clearing.Price != 10.0 || clearing.PriceSetter != &disp {
+		t.Errorf("Clear()[0] priced at %f by %v, want 10.0 by the dispatchable",
+			clearing.Price, clearing.PriceSetter)
+	}
+}
@@ -1,14 +1,61 @@
 package merit
 
+import "math"
+
 // Consumer is a merit order participant which uses energy. Dispatchable and
 // AlwaysOn plants will be used to satisfy the demand of Consumers.
+//
+// Segments optionally describes parts of the demand which are price-sensitive;
+// the remainder of the demand must always be met.
 type Consumer struct {
 	Key         string
 	Profile     [8760]float64
 	TotalDemand float64
+	Segments    []DemandSegment
+	shed        [8760]float64
 }
 
 // LoadAt returns the energy used by the Consumer in frame.
 func (c *Consumer) LoadAt(frame int) float64 {
 	return c.Profile[frame] * c.TotalDemand
 }
+
+// ShedAt returns the amount of demand which was not met in frame because the
+// price of energy exceeded what the Consumer was willing to pay.
+func (c *Consumer) ShedAt(frame int) float64 {
+	return c.shed[frame]
+}
+
+// resetAt forgets the demand shed in frame.
+func (c *Consumer) resetAt(frame int) {
+	c.shed[frame] = 0
+}
+
+// addShedAt records that demand in frame was shed.
+func (c *Consumer) addShedAt(frame int, amount float64) {
+	c.shed[frame] += amount
+}
+
+// bidsAt returns the price-sensitive segments of demand in frame.
+func (c *Consumer) bidsAt(frame int) []bid {
+	if len(c.Segments) == 0 {
+		return nil
+	}
+
+	load := c.LoadAt(frame)
+	bids := make([]bid, len(c.Segments))
+	remaining := 1.0
+
+	for i, segment := range c.Segments {
+		share := math.Min(segment.Share, remaining)
+		remaining -= share
+
+		bids[i] = bid{
+			owner:  c,
+			price:  segment.MaxPrice,
+			amount: load * share,
+		}
+	}
+
+	return bids
+}
@@ -0,0 +1,67 @@
+package merit
+
+// Charges are paid by a consumer on top of the price of energy.
+type Charges struct {
+	// Tariff is the network tariff paid for each unit of energy.
+	Tariff float64
+
+	// Tax is paid for each unit of energy.
+	Tax float64
+
+	// VAT is a share of the cost of energy, tariff and tax which is added to
+	// the total.
+	VAT float64
+}
+
+// ConsumerCosts describes what a consumer pays for energy over the year.
+//
+// Energy is the energy purchased, excluding any demand which was shed, and
+// EnergyCost its price in each frame. AveragePrice is the load-weighted
+// average price of energy, and AverageTotalPrice the same including charges.
+type ConsumerCosts struct {
+	Energy     float64
+	EnergyCost float64
+	TariffCost float64
+	TaxCost    float64
+	VATCost    float64
+	Total      float64
+
+	AveragePrice      float64
+	AverageTotalPrice float64
+}
+
+// PurchasedAt returns the energy bought by the consumer in frame: its demand
+// less any demand which was shed.
+func (c *Consumer) PurchasedAt(frame int) float64 {
+	return c.LoadAt(frame) - c.shed[frame]
+}
+
+// CostAt returns the amount paid by the consumer for energy in frame, given
+// the price of energy in the frame.
+func (c *Consumer) CostAt(frame int, price float64) float64 {
+	return c.PurchasedAt(frame) * price
+}
+
+// Costs returns what the consumer pays for energy given the price of energy in
+// each frame, such as the Prices of a calculated Order, and any charges paid
+// in addition.
+func (c *Consumer) Costs(prices []float64, charges Charges) ConsumerCosts {
+	var costs ConsumerCosts
+
+	for frame, price := range prices {
+		costs.Energy += c.PurchasedAt(frame)
+		costs.EnergyCost += c.CostAt(frame, price)
+	}
+
+	costs.TariffCost = costs.Energy * charges.Tariff
+	costs.TaxCost = costs.Energy * charges.Tax
+	costs.VATCost = (costs.EnergyCost + costs.TariffCost + costs.TaxCost) * charges.VAT
+	costs.Total = costs.EnergyCost + costs.TariffCost + costs.TaxCost + costs.VATCost
+
+	if costs.Energy > 0 {
+		costs.AveragePrice = costs.EnergyCost / costs.Energy
+		costs.AverageTotalPrice = costs.Total / costs.Energy
+	}
+
+	return costs
+}
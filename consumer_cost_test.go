@@ -0,0 +1,76 @@
+package merit
+
+import "testing"
+
+// Asserts that a consumer whose demand peaks when energy is expensive pays a
+// higher average price than one with a flat profile.
+func TestConsumerCostsByProfile(t *testing.T) {
+	cheap := Dispatchable{Cost: 1.0, Capacity: 2.0, Units: 1.0}
+	dear := Dispatchable{Cost: 5.0, Capacity: 10.0, Units: 1.0}
+
+	flat := Consumer{Profile: [8760]float64{1.0, 1.0}, TotalDemand: 1.0}
+	peaky := Consumer{Profile: [8760]float64{0.0, 2.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&flat)
+	order.AddConsumer(&peaky)
+	order.AddDispatchable(&cheap)
+	order.AddDispatchable(&dear)
+
+	Calculate(order)
+
+	tests := []struct {
+		name     string
+		consumer *Consumer
+		cost     float64
+		average  float64
+	}{
+		// Demand is 1 in frame 0 (price 1), and 3 in frame 1 (price 5).
+		{"flat", &flat, 6.0, 3.0},
+		{"peaky", &peaky, 10.0, 5.0},
+	}
+
+	for _, test := range tests {
+		costs := test.consumer.Costs(order.Prices, Charges{})
+
+		if costs.EnergyCost != test.cost {
+			t.Errorf("%s consumer EnergyCost = %f, want %f",
+				test.name, costs.EnergyCost, test.cost)
+		}
+
+		if costs.AveragePrice != test.average {
+			t.Errorf("%s consumer AveragePrice = %f, want %f",
+				test.name, costs.AveragePrice, test.average)
+		}
+	}
+}
+
+func TestConsumerCostsWithCharges(t *testing.T) {
+	cons := Consumer{Profile: [8760]float64{1.0, 1.0}, TotalDemand: 1.0}
+	costs := cons.Costs([]float64{2.0, 2.0}, Charges{Tariff: 1.0, Tax: 0.5, VAT: 0.5})
+
+	want := ConsumerCosts{
+		Energy:            2.0,
+		EnergyCost:        4.0,
+		TariffCost:        2.0,
+		TaxCost:           1.0,
+		VATCost:           3.5,
+		Total:             10.5,
+		AveragePrice:      2.0,
+		AverageTotalPrice: 5.25,
+	}
+
+	if costs != want {
+		t.Errorf("Consumer.Costs() = %+v, want %+v", costs, want)
+	}
+}
+
+// Asserts that demand which was shed is not paid for.
+func TestConsumerCostsExcludeShed(t *testing.T) {
+	cons := Consumer{Profile: [8760]float64{2.0}, TotalDemand: 1.0}
+	cons.addShedAt(0, 0.5)
+
+	if cost := cons.CostAt(0, 2.0); cost != 3.0 {
+		t.Errorf("Consumer.CostAt(0, 2.0) = %f, want 3.0", cost)
+	}
+}
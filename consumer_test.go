@@ -24,3 +24,36 @@ func TestConsumerLoadAt(t *testing.T) {
 		}
 	}
 }
+
+func TestConsumerBidsAt(t *testing.T) {
+	consumer := Consumer{
+		Profile:     [8760]float64{0: 0.5},
+		TotalDemand: 20.0,
+		Segments: []DemandSegment{
+			{Share: 0.2, MaxPrice: 10.0},
+			{Share: 0.1, MaxPrice: 5.0},
+		},
+	}
+
+	bids := consumer.bidsAt(0)
+
+	tests := []struct {
+		price, amount float64
+	}{
+		{10.0, 2.0},
+		{5.0, 1.0},
+	}
+
+	if len(bids) != len(tests) {
+		t.Fatalf("Consumer.bidsAt(0) returned %d bids, want %d",
+			len(bids), len(tests))
+	}
+
+	for i, test := range tests {
+		if bids[i].price != test.price || bids[i].amount != test.amount {
+			t.Errorf("Consumer.bidsAt(0)[%d] = {Price: %f, Amount: %f}, "+
+				"want {Price: %f, Amount: %f}", i, bids[i].price,
+				bids[i].amount, test.price, test.amount)
+		}
+	}
+}
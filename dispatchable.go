@@ -10,7 +10,22 @@ type Dispatchable struct {
 	Cost     float64
 	Capacity float64
 	Units    float64
-	load     [8760]float64
+
+	// EmissionFactor is the amount of CO2 emitted for each unit of energy
+	// produced.
+	EmissionFactor float64
+
+	// Blocks optionally splits the capacity of the dispatchable into several
+	// parts, each offered at its own cost. Any share of the capacity not
+	// covered by a block is offered at Cost. The list is held by pointer so
+	// that dispatchables may still be compared with ==.
+	Blocks *BlockList
+
+	// FixedCosts are the yearly costs of the dispatchable which do not depend
+	// on its load.
+	FixedCosts FixedCosts
+
+	load [8760]float64
 }
 
 // TotalCapacity returns the total amount of energy which may be produced by the
@@ -33,6 +48,17 @@ func (d *Dispatchable) SetLoadAt(frame int, amount float64) error {
 	return nil
 }
 
+// resetAt removes the load assigned to the dispatchable in frame.
+func (d *Dispatchable) resetAt(frame int) {
+	d.load[frame] = 0
+}
+
+// addLoadAt increases the load of the dispatchable in the chosen frame. Used
+// when more than one block of the dispatchable is running.
+func (d *Dispatchable) addLoadAt(frame int, amount float64) {
+	d.load[frame] += amount
+}
+
 // LoadAt returns the load of the dispatchable in frame. May return nil if no
 // load is yet assigned.
 func (d *Dispatchable) LoadAt(frame int) float64 {
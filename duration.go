@@ -0,0 +1,84 @@
+package merit
+
+import "sort"
+
+// DurationPoint is a value in a duration curve, and the frame in which it
+// occurred.
+type DurationPoint struct {
+	Frame int
+	Value float64
+}
+
+// DurationCurve contains a value for every frame of the year, sorted from the
+// highest to the lowest. Frames with equal values remain in frame order.
+type DurationCurve []DurationPoint
+
+func (c DurationCurve) Len() int {
+	return len(c)
+}
+
+func (c DurationCurve) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+func (c DurationCurve) Less(i, j int) bool {
+	return c[i].Value > c[j].Value
+}
+
+// Values returns the sorted values of the curve, without their frames.
+func (c DurationCurve) Values() []float64 {
+	values := make([]float64, len(c))
+
+	for i, point := range c {
+		values[i] = point.Value
+	}
+
+	return values
+}
+
+// newDurationCurve builds a duration curve of the value in each frame.
+func newDurationCurve(valueAt func(frame int) float64) DurationCurve {
+	curve := make(DurationCurve, 8760)
+
+	for frame := range curve {
+		curve[frame] = DurationPoint{Frame: frame, Value: valueAt(frame)}
+	}
+
+	sort.Stable(curve)
+
+	return curve
+}
+
+// LoadDurationCurve returns the duration curve of the load of a participant.
+func LoadDurationCurve(p Participant) DurationCurve {
+	return newDurationCurve(p.LoadAt)
+}
+
+// ResidualLoadAt returns the demand in frame which is not met by always-on
+// producers. It is negative when always-on production exceeds demand.
+func (o *Order) ResidualLoadAt(frame int) float64 {
+	residual := o.DemandAt(frame)
+
+	for _, producer := range o.producers() {
+		residual -= producer.LoadAt(frame)
+	}
+
+	return residual
+}
+
+// DemandDurationCurve returns the duration curve of the demand of the order.
+func (o *Order) DemandDurationCurve() DurationCurve {
+	return newDurationCurve(o.DemandAt)
+}
+
+// ResidualLoadDurationCurve returns the duration curve of the residual load of
+// the order.
+func (o *Order) ResidualLoadDurationCurve() DurationCurve {
+	return newDurationCurve(o.ResidualLoadAt)
+}
+
+// PriceDurationCurve returns the duration curve of the price of energy in a
+// calculated order.
+func (o *Order) PriceDurationCurve() DurationCurve {
+	return newDurationCurve(o.PriceAt)
+}
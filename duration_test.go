@@ -0,0 +1,71 @@
+package merit
+
+import "testing"
+
+func assertCurveStart(t *testing.T, name string, curve DurationCurve, want []DurationPoint) {
+	if len(curve) != 8760 {
+		t.Fatalf("%s has %d points, want 8760", name, len(curve))
+	}
+
+	for i, point := range want {
+		if curve[i] != point {
+			t.Errorf("%s[%d] = %+v, want %+v", name, i, curve[i], point)
+		}
+	}
+}
+
+func TestDemandDurationCurve(t *testing.T) {
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0, 3.0, 2.0, 3.0}, TotalDemand: 1.0})
+
+	assertCurveStart(t, "DemandDurationCurve", order.DemandDurationCurve(), []DurationPoint{
+		{1, 3.0},
+		{3, 3.0}, // Equal values remain in frame order.
+		{2, 2.0},
+		{0, 1.0},
+		{4, 0.0},
+	})
+}
+
+func TestResidualLoadDurationCurve(t *testing.T) {
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0, 3.0, 2.0}, TotalDemand: 1.0})
+	order.AddAlwaysOn(&AlwaysOn{Profile: [8760]float64{0.0, 2.5, 0.5, 1.0}, TotalProduction: 1.0})
+
+	curve := order.ResidualLoadDurationCurve()
+
+	assertCurveStart(t, "ResidualLoadDurationCurve", curve, []DurationPoint{
+		{2, 1.5},
+		{0, 1.0},
+		{1, 0.5},
+	})
+
+	if last := curve[len(curve)-1]; last != (DurationPoint{3, -1.0}) {
+		t.Errorf("ResidualLoadDurationCurve ends with %+v, want {3 -1}", last)
+	}
+}
+
+func TestPriceDurationCurve(t *testing.T) {
+	order := NewOrder()
+	order.Prices[5] = 2.0
+	order.Prices[7] = 4.0
+
+	assertCurveStart(t, "PriceDurationCurve", order.PriceDurationCurve(), []DurationPoint{
+		{7, 4.0},
+		{5, 2.0},
+		{0, 0.0},
+	})
+}
+
+func TestLoadDurationCurve(t *testing.T) {
+	disp := Dispatchable{Capacity: 1.0, Units: 1.0}
+	disp.SetLoadAt(2, 0.5)
+
+	curve := LoadDurationCurve(&disp)
+
+	assertCurveStart(t, "LoadDurationCurve", curve, []DurationPoint{{2, 0.5}, {0, 0.0}})
+
+	if values := curve.Values(); values[0] != 0.5 || values[1] != 0.0 {
+		t.Errorf("DurationCurve.Values() starts %v, want [0.5 0]", values[:2])
+	}
+}
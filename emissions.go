@@ -0,0 +1,92 @@
+package merit
+
+// EmissionsAt returns the amount of CO2 emitted by the dispatchable in frame.
+func (d *Dispatchable) EmissionsAt(frame int) float64 {
+	return d.load[frame] * d.EmissionFactor
+}
+
+// TotalEmissions returns the amount of CO2 emitted by the dispatchable over
+// the whole year.
+func (d *Dispatchable) TotalEmissions() float64 {
+	var sum float64
+
+	for frame := range d.load {
+		sum += d.EmissionsAt(frame)
+	}
+
+	return sum
+}
+
+// EmissionsAt returns the amount of CO2 emitted by all dispatchables in the
+// merit order in frame.
+func (o *Order) EmissionsAt(frame int) float64 {
+	var sum float64
+
+	for _, producer := range o.Dispatchables {
+		sum += producer.EmissionsAt(frame)
+	}
+
+	return sum
+}
+
+// TotalEmissions returns the amount of CO2 emitted by all dispatchables in the
+// merit order over the whole year.
+func (o *Order) TotalEmissions() float64 {
+	var sum float64
+
+	for _, producer := range o.Dispatchables {
+		sum += producer.TotalEmissions()
+	}
+
+	return sum
+}
+
+// AverageIntensityAt returns the average amount of CO2 emitted for each unit
+// of energy supplied in frame by always-on producers, flexibles, dispatchables
+// and imports, less any production which was curtailed. Returns zero when no
+// energy was supplied.
+func (o *Order) AverageIntensityAt(frame int) float64 {
+	balance := o.BalanceAt(frame)
+	supplied := balance.Supply() - balance.Unmet - balance.Curtailed
+
+	if supplied <= 0 {
+		return 0.0
+	}
+
+	return o.EmissionsAt(frame) / supplied
+}
+
+// MarginalIntensityAt returns the emission factor of the dispatchable which
+// set the price in frame. Returns zero when the price was set by any other
+// participant, or no price setter was assigned.
+func (o *Order) MarginalIntensityAt(frame int) float64 {
+	if setter, ok := o.PriceSetters[frame].(*Dispatchable); ok {
+		return setter.EmissionFactor
+	}
+
+	return 0.0
+}
+
+// AverageIntensityCurve returns the average emission intensity of the energy
+// supplied in every frame.
+func (o *Order) AverageIntensityCurve() [8760]float64 {
+	var curve [8760]float64
+
+	for frame := range curve {
+		curve[frame] = o.AverageIntensityAt(frame)
+	}
+
+	return curve
+}
+
+// MarginalIntensityCurve returns the emission factor of the price-setting
+// dispatchable in every frame.
+func (o *Order) MarginalIntensityCurve() [8760]float64 {
+	var curve [8760]float64
+
+	for frame := range curve {
+		curve[frame] = o.MarginalIntensityAt(frame)
+	}
+
+	return curve
+}
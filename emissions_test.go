@@ -0,0 +1,123 @@
+package merit
+
+import "testing"
+
+func TestDispatchableEmissions(t *testing.T) {
+	disp := Dispatchable{EmissionFactor: 0.5}
+
+	disp.SetLoadAt(0, 2.0)
+	disp.SetLoadAt(1, 4.0)
+
+	tests := []struct {
+		frame int
+		want  float64
+	}{
+		{0, 1.0},
+		{1, 2.0},
+		{2, 0.0},
+	}
+
+	for _, test := range tests {
+		if emissions := disp.EmissionsAt(test.frame); emissions != test.want {
+			t.Errorf("Dispatchable.EmissionsAt(%d) = %f, want %f",
+				test.frame, emissions, test.want)
+		}
+	}
+
+	if total := disp.TotalEmissions(); total != 3.0 {
+		t.Errorf("Dispatchable.TotalEmissions() = %f, want 3.0", total)
+	}
+}
+
+func TestOrderEmissions(t *testing.T) {
+	clean := Dispatchable{Key: "clean", Cost: 1.0, Capacity: 1.0, Units: 1.0}
+	dirty := Dispatchable{
+		Key:            "dirty",
+		Cost:           2.0,
+		Capacity:       2.0,
+		Units:          1.0,
+		EmissionFactor: 0.8,
+	}
+
+	cons := Consumer{Profile: [8760]float64{0.5, 2.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&dirty)
+	order.AddDispatchable(&clean)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame    int
+		total    float64
+		average  float64
+		marginal float64
+	}{
+		{0, 0.0, 0.0, 0.0}, // Clean dispatchable sets the price.
+		{1, 0.8, 0.4, 0.8}, // Dirty dispatchable sets the price.
+		{2, 0.0, 0.0, 0.0}, // No demand.
+	}
+
+	for _, test := range tests {
+		if total := order.EmissionsAt(test.frame); total != test.total {
+			t.Errorf("Order.EmissionsAt(%d) = %f, want %f",
+				test.frame, total, test.total)
+		}
+
+		if average := order.AverageIntensityAt(test.frame); average != test.average {
+			t.Errorf("Order.AverageIntensityAt(%d) = %f, want %f",
+				test.frame, average, test.average)
+		}
+
+		if marginal := order.MarginalIntensityAt(test.frame); marginal != test.marginal {
+			t.Errorf("Order.MarginalIntensityAt(%d) = %f, want %f",
+				test.frame, marginal, test.marginal)
+		}
+	}
+
+	if total := order.TotalEmissions(); total != 0.8 {
+		t.Errorf("Order.TotalEmissions() = %f, want 0.8", total)
+	}
+
+	if curve := order.MarginalIntensityCurve(); curve[1] != 0.8 {
+		t.Errorf("Order.MarginalIntensityCurve()[1] = %f, want 0.8", curve[1])
+	}
+
+	if curve := order.AverageIntensityCurve(); curve[1] != 0.4 {
+		t.Errorf("Order.AverageIntensityCurve()[1] = %f, want 0.4", curve[1])
+	}
+}
+
+// Asserts that the average intensity is that of the energy supplied, rather
+// than of the demand.
+func TestOrderAverageIntensitySupplied(t *testing.T) {
+	dirty := Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0, EmissionFactor: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{3.0, 2.0}, TotalDemand: 1.0})
+	order.AddDispatchable(&dirty)
+	order.AddImport(&Import{
+		Capacity:     1.0,
+		Units:        1.0,
+		Prices:       [8760]float64{5.0, 5.0},
+		Availability: [8760]float64{0.0, 1.0},
+	})
+
+	Calculate(order)
+
+	tests := []struct {
+		frame int
+		want  float64
+	}{
+		{0, 1.0}, // Demand is unmet.
+		{1, 0.5}, // Half of the energy is imported.
+	}
+
+	for _, test := range tests {
+		if average := order.AverageIntensityAt(test.frame); average != test.want {
+			t.Errorf("Order.AverageIntensityAt(%d) = %f, want %f",
+				test.frame, average, test.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package merit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepKind identifies what happened in a Step of a frame.
+type StepKind string
+
+const (
+	StepDemand       StepKind = "demand"
+	StepAlwaysOn     StepKind = "always-on"
+	StepExcess       StepKind = "excess"
+	StepDischarge    StepKind = "discharge"
+	StepChargeBid    StepKind = "charge-bid"
+	StepDispatchable StepKind = "dispatchable"
+	StepShed         StepKind = "shed"
+	StepUnmet        StepKind = "unmet"
+	StepPrice        StepKind = "price"
+)
+
+// Step is something which happened while a frame was calculated: demand was
+// met, excess was absorbed, or the price was set.
+//
+// Amount is the energy involved, and Remaining the demand still to be met
+// afterwards. Offered is the energy offered to a flexible absorbing excess, and
+// Price the cost of a dispatchable, the price of shed demand, or the price set
+// in the frame. Reason describes why the participant stopped.
+type Step struct {
+	Kind        StepKind    `json:"kind"`
+	Participant Participant `json:"-"`
+	Key         string      `json:"participant,omitempty"`
+	Amount      float64     `json:"amount"`
+	Offered     float64     `json:"offered,omitempty"`
+	Price       float64     `json:"price,omitempty"`
+	Remaining   float64     `json:"remaining"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// Explanation is the ordered trace of every step taken to calculate a frame.
+type Explanation struct {
+	Frame       int     `json:"frame"`
+	Steps       []Step  `json:"steps"`
+	PriceSetter string  `json:"price_setter,omitempty"`
+	Price       float64 `json:"price"`
+}
+
+// Copier may be implemented by a Flexible, Bidder or Supplier of a type defined
+// outside this package, so that ExplainFrame may calculate a frame on a copy of
+// it. Copy returns a copy which implements the same interfaces, and shares no
+// results with the original.
+type Copier interface {
+	Copy() Participant
+}
+
+// ExplainFrame calculates frame of an order which has already been calculated,
+// and returns each step taken. The frame is calculated on a copy of the order
+// and its participants, leaving the results of the order unchanged.
+//
+// Orders which are part of a Network, or which were cleared with Clear, are
+// explained as though they were calculated alone.
+//
+// An error is returned, and nothing is calculated, when a Flexible, Bidder or
+// Supplier of a type defined outside this package does not implement Copier.
+func ExplainFrame(order Order, frame int) (Explanation, error) {
+	twin, originals, err := order.copyAt(frame)
+
+	if err != nil {
+		return Explanation{Frame: frame}, err
+	}
+
+	recorder := &stepRecorder{}
+
+	prepare(&twin)
+
+	// The observers of the order are not notified; the recorder never returns
+	// an error.
+	runFrame(twin, &FrameState{Frame: frame, observers: []Observer{recorder}})
+
+	for i, step := range recorder.steps {
+		if original, ok := originals[step.Participant]; ok {
+			recorder.steps[i].Participant = original
+		}
+	}
+
+	explanation := Explanation{
+		Frame: frame,
+		Steps: recorder.steps,
+		Price: twin.PriceAt(frame),
+	}
+
+	if setter := twin.PriceSetters[frame]; setter != nil {
+		explanation.PriceSetter = nameOf(setter)
+	}
+
+	return explanation, nil
+}
+
+// copyAt returns a copy of the order, and of each participant which records the
+// outcome of a frame, ready for frame to be calculated again. The map relates
+// each copied participant to the original.
+func (o Order) copyAt(frame int) (Order, map[Participant]Participant, error) {
+	twin := o
+	originals := make(map[Participant]Participant)
+
+	twin.PriceSetters = make([]Participant, 8760)
+	twin.Prices = make([]float64, 8760)
+	twin.Unmet = make([]float64, 8760)
+	twin.Curtailed = make([]float64, 8760)
+	twin.Observers = nil
+	twin.tracker = nil
+
+	twin.Consumers = nil
+
+	for _, consumer := range o.Consumers {
+		dup := *consumer
+		originals[&dup] = consumer
+		twin.Consumers = append(twin.Consumers, &dup)
+	}
+
+	twin.Flexibles = make([]Flexible, len(o.Flexibles))
+
+	for i, flex := range o.Flexibles {
+		switch f := flex.(type) {
+		case *Flex:
+			dup := *f
+			dup.load[frame] = 0
+			originals[&dup] = f
+			twin.Flexibles[i] = &dup
+		case *Sink:
+			dup := *f
+			originals[&dup] = f
+			twin.Flexibles[i] = &dup
+		case *Storage:
+			// The reserve returns to the amount stored at the start of the
+			// frame.
+			dup := *f
+			dup.reserve.Set(frame, dup.reserve.At(frame)+dup.load[frame])
+			dup.load[frame] = 0
+			originals[&dup] = f
+			twin.Flexibles[i] = &dup
+		default:
+			dup, err := copyOther(flex)
+
+			if err != nil {
+				return o, nil, err
+			}
+
+			originals[dup] = flex
+			twin.Flexibles[i] = dup
+		}
+	}
+
+	twin.Demands = make([]Participant, len(o.Demands))
+
+	for i, demand := range o.Demands {
+		twin.Demands[i] = demand
+
+		// Demand which does not bid records nothing.
+		if bidder, ok := demand.(Bidder); ok {
+			dup, err := copyOther(bidder)
+
+			if err != nil {
+				return o, nil, err
+			}
+
+			originals[dup] = demand
+			twin.Demands[i] = dup
+		}
+	}
+
+	twin.Suppliers = make([]Supplier, len(o.Suppliers))
+
+	for i, supplier := range o.Suppliers {
+		dup, err := copyOther(supplier)
+
+		if err != nil {
+			return o, nil, err
+		}
+
+		originals[dup] = supplier
+		twin.Suppliers[i] = dup
+	}
+
+	twin.Dispatchables = make(DispatchableList, len(o.Dispatchables))
+
+	for i, producer := range o.Dispatchables {
+		dup := *producer
+		originals[&dup] = producer
+		twin.Dispatchables[i] = &dup
+	}
+
+	twin.Imports = make([]*Import, len(o.Imports))
+
+	for i, imp := range o.Imports {
+		dup := *imp
+		originals[&dup] = imp
+		twin.Imports[i] = &dup
+	}
+
+	twin.Exports = make([]*Export, len(o.Exports))
+
+	for i, export := range o.Exports {
+		dup := *export
+		originals[&dup] = export
+		twin.Exports[i] = &dup
+	}
+
+	return twin, originals, nil
+}
+
+// copyOther returns a copy of a participant of a type defined outside this
+// package, made by its Copier.
+func copyOther[P Participant](p P) (P, error) {
+	if c, ok := Participant(p).(Copier); ok {
+		if dup, ok := c.Copy().(P); ok {
+			return dup, nil
+		}
+	}
+
+	return p, fmt.Errorf(
+		"ExplainFrame: Participant %q cannot be copied", nameOf(p))
+}
+
+// String renders the explanation as text, with one line per step.
+func (e Explanation) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Frame %d\n", e.Frame)
+
+	for _, step := range e.Steps {
+		fmt.Fprintf(&b, "  %-13s %-20s %12.4f  remaining %12.4f",
+			step.Kind, step.Key, step.Amount, step.Remaining)
+
+		if step.Offered != 0 {
+			fmt.Fprintf(&b, "  offered %.4f", step.Offered)
+		}
+
+		if step.Price != 0 {
+			fmt.Fprintf(&b, "  price %.4f", step.Price)
+		}
+
+		if step.Reason != "" {
+			fmt.Fprintf(&b, "  (%s)", step.Reason)
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// nameOf returns the Key of a participant, or its type when it has none.
+func nameOf(p Participant) string {
+	if key := KeyOf(p); key != "" {
+		return key
+	}
+
+	return fmt.Sprintf("%T", p)
+}
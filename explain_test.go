@@ -0,0 +1,200 @@
+package merit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func explainOrder() (Order, *Storage) {
+	storage := &Storage{
+		Flex:    Flex{Key: "battery", Capacity: 1.0, Units: 1.0},
+		reserve: NewReserveWithoutDecay(10.0),
+	}
+
+	order := NewOrder()
+
+	order.AddConsumer(&Consumer{Key: "household", Profile: [8760]float64{1.0, 6.0}, TotalDemand: 1.0})
+	order.AddConsumer(&Consumer{
+		Key:         "industry",
+		Profile:     [8760]float64{0.0, 1.0},
+		TotalDemand: 1.0,
+		Segments:    []DemandSegment{{Share: 1.0, MaxPrice: 2.0}},
+	})
+
+	order.AddAlwaysOn(&AlwaysOn{Key: "solar", Profile: [8760]float64{3.0, 1.0}, TotalProduction: 1.0})
+	order.AddStorage(storage)
+	order.AddDispatchable(&Dispatchable{Key: "coal", Cost: 1.0, Capacity: 2.0, Units: 1.0})
+	order.AddDispatchable(&Dispatchable{Key: "gas", Cost: 3.0, Capacity: 4.0, Units: 1.0})
+
+	Calculate(order)
+
+	return order, storage
+}
+
+func TestExplainFrame(t *testing.T) {
+	order, storage := explainOrder()
+
+	stored := storage.StoredAt(1)
+	explanation, err := ExplainFrame(order, 1)
+
+	if err != nil {
+		t.Fatalf("ExplainFrame returned an error: %s", err)
+	}
+
+	want := []struct {
+		kind   StepKind
+		key    string
+		amount float64
+		reason string
+	}{
+		{StepDemand, "", 7.0, ""},
+		{StepAlwaysOn, "solar", 1.0, ""},
+		{StepDischarge, "battery", 1.0, "at capacity"},
+		{StepDispatchable, "coal", 2.0, "at capacity"},
+		{StepShed, "industry", 1.0, "price exceeds bid"},
+		{StepDispatchable, "gas", 2.0, "demand met"},
+		{StepPrice, "gas", 0.0, ""},
+	}
+
+	if len(explanation.Steps) != len(want) {
+		t.Fatalf("ExplainFrame(order, 1) has %d steps, want %d:\n%s",
+			len(explanation.Steps), len(want), explanation)
+	}
+
+	for i, step := range explanation.Steps {
+		if step.Kind != want[i].kind || step.Key != want[i].key ||
+			step.Amount != want[i].amount || step.Reason != want[i].reason {
+			t.Errorf("Step %d = %+v, want %+v", i, step, want[i])
+		}
+	}
+
+	if explanation.PriceSetter != "gas" || explanation.Price != 3.0 {
+		t.Errorf("ExplainFrame price = %s at %f, want gas at 3.0",
+			explanation.PriceSetter, explanation.Price)
+	}
+
+	if storage.StoredAt(1) != stored {
+		t.Errorf("StoredAt(1) after ExplainFrame = %f, want %f",
+			storage.StoredAt(1), stored)
+	}
+}
+
+// Asserts that explaining a frame after the order has changed leaves the order
+// and its results as they were.
+func TestExplainFrameLeavesOrder(t *testing.T) {
+	order, storage := explainOrder()
+
+	coal, gas := order.Dispatchables[0], order.Dispatchables[1]
+	coalLoad, gasLoad := coal.LoadAt(1), gas.LoadAt(1)
+	stored, price := storage.StoredAt(1), order.PriceAt(1)
+
+	// Gas is now cheaper than coal, and is used first.
+	gas.Cost = 0.5
+	explanation, err := ExplainFrame(order, 1)
+
+	if err != nil {
+		t.Fatalf("ExplainFrame returned an error: %s", err)
+	}
+
+	if explanation.Price != 1.0 || explanation.PriceSetter != "coal" {
+		t.Errorf("ExplainFrame price = %s at %f, want coal at 1.0",
+			explanation.PriceSetter, explanation.Price)
+	}
+
+	if explanation.Steps[3].Participant != gas {
+		t.Errorf("Step 3 participant = %p, want gas %p",
+			explanation.Steps[3].Participant, gas)
+	}
+
+	if order.Dispatchables[0] != coal || order.Dispatchables[1] != gas {
+		t.Errorf("ExplainFrame re-sorted the dispatchables of the order")
+	}
+
+	if coal.LoadAt(1) != coalLoad || gas.LoadAt(1) != gasLoad ||
+		storage.StoredAt(1) != stored || order.PriceAt(1) != price {
+		t.Errorf("ExplainFrame changed the results of frame 1")
+	}
+}
+
+// Asserts that participants of other types are explained on a copy, and that
+// an order with a participant which cannot be copied is not explained.
+func TestExplainFrameCustomParticipants(t *testing.T) {
+	custom := &sponge{}
+
+	order := NewOrder()
+	order.AddDemand(fixedDemand(1.0))
+	order.AddProducer(fixedProducer(3.0))
+	order.AddFlexible(custom)
+
+	Calculate(order)
+
+	explanation, err := ExplainFrame(order, 0)
+
+	if err != nil {
+		t.Fatalf("ExplainFrame returned an error: %s", err)
+	}
+
+	if step := explanation.Steps[2]; step.Kind != StepExcess || step.Participant != custom {
+		t.Errorf("Step 2 = %+v, want excess absorbed by the custom flexible", step)
+	}
+
+	if load := custom.LoadAt(0); load != -2.0 {
+		t.Errorf("LoadAt(0) after ExplainFrame = %f, want -2.0", load)
+	}
+
+	uncopied := &fleet{available: 1.0}
+	order.AddFlexible(uncopied)
+
+	if _, err := ExplainFrame(order, 0); err == nil {
+		t.Errorf("ExplainFrame with a flexible which cannot be copied returned no error")
+	}
+
+	if load := custom.LoadAt(0); load != -2.0 {
+		t.Errorf("LoadAt(0) after a failed ExplainFrame = %f, want -2.0", load)
+	}
+}
+
+func TestExplainFrameExcess(t *testing.T) {
+	order, _ := explainOrder()
+	explanation, err := ExplainFrame(order, 0)
+
+	if err != nil {
+		t.Fatalf("ExplainFrame returned an error: %s", err)
+	}
+
+	step := explanation.Steps[2]
+
+	if step.Kind != StepExcess || step.Amount != 1.0 || step.Offered != 2.0 {
+		t.Errorf("Step 2 = %+v, want 1.0 of 2.0 excess absorbed by battery", step)
+	}
+}
+
+func TestExplanationRendering(t *testing.T) {
+	order, _ := explainOrder()
+	explanation, err := ExplainFrame(order, 1)
+
+	if err != nil {
+		t.Fatalf("ExplainFrame returned an error: %s", err)
+	}
+
+	text := explanation.String()
+
+	for _, want := range []string{"Frame 1\n", "dispatchable", "(demand met)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Explanation.String() does not contain %q:\n%s", want, text)
+		}
+	}
+
+	out, err := json.Marshal(explanation)
+
+	if err != nil {
+		t.Fatalf("json.Marshal(explanation) returned an error: %s", err)
+	}
+
+	for _, want := range []string{`"kind":"shed"`, `"participant":"industry"`, `"price_setter":"gas"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("JSON does not contain %s: %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package merit
+
+import (
+	"math"
+	"sort"
+)
+
+// FixedCosts are the yearly costs of a producer which do not depend on how
+// much energy it produces.
+type FixedCosts struct {
+	// Operation is the yearly cost of operation and maintenance.
+	Operation float64
+
+	// Annuity is the yearly cost of the capital invested in the producer.
+	Annuity float64
+}
+
+// Total returns the sum of the fixed costs.
+func (c FixedCosts) Total() float64 {
+	return c.Operation + c.Annuity
+}
+
+// Financials describes the income and costs of a producer over the year.
+//
+// Revenue is the price of energy multiplied by the load in each frame, and
+// VariableCost the cost of producing that energy. OperatingMargin is the
+// difference between the two, and NetProfit what remains after paying the
+// fixed costs. A producer with a negative NetProfit would not remain in the
+// market.
+type Financials struct {
+	Revenue         float64
+	VariableCost    float64
+	OperatingMargin float64
+	FixedCost       float64
+	NetProfit       float64
+}
+
+// newFinancials computes the margin and profit from the revenue and costs.
+func newFinancials(revenue, variable float64, fixed FixedCosts) Financials {
+	margin := revenue - variable
+
+	return Financials{
+		Revenue:         revenue,
+		VariableCost:    variable,
+		OperatingMargin: margin,
+		FixedCost:       fixed.Total(),
+		NetProfit:       margin - fixed.Total(),
+	}
+}
+
+// Financials returns the income and costs of the dispatchable given the price
+// of energy in each frame, such as the Prices of a calculated Order.
+func (d *Dispatchable) Financials(prices []float64) Financials {
+	var revenue, variable float64
+
+	offers := offerList(d.offers())
+	sort.Stable(offers)
+
+	for frame, price := range prices {
+		load := d.load[frame]
+		revenue += price * load
+
+		// The cheapest blocks of the dispatchable are always used first.
+		for _, offer := range offers {
+			if load <= 0 {
+				break
+			}
+
+			used := math.Min(load, offer.capacity)
+			variable += used * offer.cost
+			load -= used
+		}
+	}
+
+	return newFinancials(revenue, variable, d.FixedCosts)
+}
+
+// Financials returns the income and costs of the always-on producer given the
+// price of energy in each frame. Always-on producers have no variable cost.
+func (a *AlwaysOn) Financials(prices []float64) Financials {
+	var revenue float64
+
+	for frame, price := range prices {
+		revenue += price * a.LoadAt(frame)
+	}
+
+	return newFinancials(revenue, 0, a.FixedCosts)
+}
+
+// Financials returns the income and costs of the storage given the price of
+// energy in each frame. The variable cost is the price paid for the energy
+// charged.
+func (s *Storage) Financials(prices []float64) Financials {
+	var revenue, variable float64
+
+	for frame, price := range prices {
+		if load := s.load[frame]; load > 0 {
+			revenue += price * load
+		} else {
+			variable -= price * load
+		}
+	}
+
+	return newFinancials(revenue, variable, s.FixedCosts)
+}
@@ -0,0 +1,67 @@
+package merit
+
+import "testing"
+
+func assertFinancials(t *testing.T, name string, got, want Financials) {
+	if got != want {
+		t.Errorf("%s.Financials() = %+v, want %+v", name, got, want)
+	}
+}
+
+// Asserts that the cheapest block of a dispatchable is used first when
+// computing its variable cost.
+func TestDispatchableFinancials(t *testing.T) {
+	disp := Dispatchable{
+		Cost:       3.0,
+		Capacity:   2.0,
+		Units:      1.0,
+		Blocks:     &BlockList{{Share: 0.5, Cost: 1.0}},
+		FixedCosts: FixedCosts{Operation: 1.0, Annuity: 2.0},
+	}
+
+	disp.SetLoadAt(0, 0.5)
+	disp.SetLoadAt(1, 2.0)
+
+	prices := []float64{1.0, 4.0}
+
+	assertFinancials(t, "Dispatchable", disp.Financials(prices), Financials{
+		Revenue:         8.5,
+		VariableCost:    4.5,
+		OperatingMargin: 4.0,
+		FixedCost:       3.0,
+		NetProfit:       1.0,
+	})
+}
+
+func TestAlwaysOnFinancials(t *testing.T) {
+	ao := AlwaysOn{
+		Profile:         [8760]float64{1.0, 2.0},
+		TotalProduction: 1.0,
+		FixedCosts:      FixedCosts{Annuity: 5.0},
+	}
+
+	assertFinancials(t, "AlwaysOn", ao.Financials([]float64{1.0, 1.5}), Financials{
+		Revenue:         4.0,
+		OperatingMargin: 4.0,
+		FixedCost:       5.0,
+		NetProfit:       -1.0,
+	})
+}
+
+// Asserts that the energy charged by a storage is a cost.
+func TestStorageFinancials(t *testing.T) {
+	storage := Storage{
+		Flex:    Flex{Capacity: 1.0, Units: 1.0},
+		reserve: NewReserveWithoutDecay(5.0),
+	}
+
+	storage.AssignExcessAt(0, 1.0)
+	storage.SetLoadAt(1, 1.0)
+
+	assertFinancials(t, "Storage", storage.Financials([]float64{1.0, 3.0}), Financials{
+		Revenue:         3.0,
+		VariableCost:    1.0,
+		OperatingMargin: 2.0,
+		NetProfit:       2.0,
+	})
+}
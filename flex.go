@@ -2,17 +2,25 @@ package merit
 
 import "fmt"
 
-type Flexlike interface {
-	AssignExcessAt(int, float64) float64
-	SetLoadAt(int, float64) error
-	AvailableAt(int) float64
-}
+// Flexlike is the former name of Flexible.
+//
+// Deprecated: use Flexible instead.
+type Flexlike = Flexible
 
 type Flex struct {
 	Key      string
 	Capacity float64
 	Units    float64
-	load     [8760]float64
+
+	// ChargePrice is the price the flex is willing to pay for energy. Flexibles
+	// with higher prices are offered excess energy first.
+	ChargePrice float64
+
+	// DischargePrice is the price at which the flex is willing to produce
+	// energy. Flexibles with lower prices are discharged first.
+	DischargePrice float64
+
+	load [8760]float64
 }
 
 // TotalCapacity returns the total amount of energy which may be produced or
@@ -21,6 +29,16 @@ func (f *Flex) TotalCapacity() float64 {
 	return f.Capacity * f.Units
 }
 
+// ChargingPrice returns the price the flex is willing to pay for energy.
+func (f *Flex) ChargingPrice() float64 {
+	return f.ChargePrice
+}
+
+// DischargingPrice returns the price at which the flex will produce energy.
+func (f *Flex) DischargingPrice() float64 {
+	return f.DischargePrice
+}
+
 func (f *Flex) AvailableAt(frame int) float64 {
 	return 0.0
 }
@@ -61,9 +79,45 @@ func (f *Flex) LoadAt(frame int) float64 {
 	return f.load[frame]
 }
 
+// forget discards the load of the flex in frames start to end (exclusive), so
+// that they may be calculated again.
+func (f *Flex) forget(start, end int) {
+	for frame := start; frame < end; frame++ {
+		f.load[frame] = 0
+	}
+}
+
+// StoragePricing determines the price of energy when a Storage meets the last
+// of the demand in a frame.
+type StoragePricing int
+
+const (
+	// NoPricing leaves the price to be set by the cheapest dispatchable, as
+	// for any other flexible.
+	NoPricing StoragePricing = iota
+
+	// DischargePricing prices energy at the DischargePrice of the storage.
+	DischargePricing
+
+	// ChargingCostPricing prices energy at the average price paid for the
+	// energy in the storage. Energy lost to decay raises the price of the
+	// energy which remains.
+	ChargingCostPricing
+
+	// OpportunityCostPricing prices energy at the cost of the dispatchable
+	// which would otherwise have met the demand.
+	OpportunityCostPricing
+)
+
 type Storage struct {
 	Flex
-	reserve reserve
+	Pricing    StoragePricing
+	FixedCosts FixedCosts
+	reserve    reserve
+
+	// value is the amount paid for the energy in the reserve at the end of
+	// each frame.
+	value [8760]float64
 }
 
 func (s *Storage) AssignExcessAt(frame int, amount float64) float64 {
@@ -95,13 +149,109 @@ func (s *Storage) SetLoadAt(frame int, amount float64) error {
 	return nil
 }
 
+// forget discards the results of the storage in frames start to end
+// (exclusive), so that they may be calculated again.
+func (s *Storage) forget(start, end int) {
+	s.reserve.forget(start, end)
+
+	for frame := start; frame < end; frame++ {
+		s.load[frame] = 0
+		s.value[frame] = 0
+	}
+}
+
+// seedAt sets the energy held by the storage at the start of frame, after any
+// decay in that frame. The energy is treated as having been charged for free,
+// which requires the value of the frame before to be zero while frame is
+// calculated; the returned function restores it.
+func (s *Storage) seedAt(frame int, amount float64) (restore func()) {
+	s.reserve.Set(frame, amount)
+
+	if frame == 0 {
+		return func() {}
+	}
+
+	value := s.value[frame-1]
+	s.value[frame-1] = 0
+
+	return func() { s.value[frame-1] = value }
+}
+
+// StoredAt returns the energy stored at the end of frame.
+func (s *Storage) StoredAt(frame int) float64 {
+	return s.reserve.At(frame)
+}
+
 func (s *Storage) AvailableAt(frame int) float64 {
-	available := s.reserve.At(frame)
+	return s.availableFrom(s.reserve.At(frame))
+}
+
+// availableFrom returns how much energy the storage may produce when it holds
+// the stored amount.
+func (s *Storage) availableFrom(stored float64) float64 {
 	capacity := s.Capacity * s.Units
 
-	if available > capacity {
+	if stored > capacity {
 		return capacity
 	}
 
-	return available
+	return stored
+}
+
+// PriceAt returns the price set by the storage when it meets the last of the
+// demand in frame, according to its Pricing. Returns false when the Pricing is
+// NoPricing.
+func (s *Storage) PriceAt(frame int, replacement float64) (float64, bool) {
+	switch s.Pricing {
+	case DischargePricing:
+		return s.DischargePrice, true
+	case ChargingCostPricing:
+		return s.chargingCostAt(frame), true
+	case OpportunityCostPricing:
+		return replacement, true
+	}
+
+	return 0, false
+}
+
+// chargingCostAt returns the average price paid for each unit of energy in the
+// storage at the start of frame.
+func (s *Storage) chargingCostAt(frame int) float64 {
+	stored := s.reserve.At(frame) + s.load[frame]
+
+	if stored <= 0 {
+		return 0
+	}
+
+	return s.valueBefore(frame) / stored
+}
+
+// observePriceAt records the price paid for energy charged in frame, and the
+// value removed from the storage by any discharge.
+func (s *Storage) observePriceAt(frame int, price float64) {
+	value := s.valueBefore(frame)
+	load := s.load[frame]
+
+	switch {
+	case load < 0:
+		value += -load * price
+	case load > 0:
+		value *= s.reserve.At(frame) / (s.reserve.At(frame) + load)
+	}
+
+	if s.reserve.At(frame) <= 0 {
+		value = 0
+	}
+
+	s.value[frame] = value
+}
+
+// valueBefore returns the amount paid for the energy in the storage at the end
+// of the frame before frame.
+func (s *Storage) valueBefore(frame int) float64 {
+	if frame == 0 {
+		return 0
+	}
+
+	return s.value[frame-1]
 }
@@ -0,0 +1,93 @@
+package merit
+
+import "sort"
+
+// Priced is implemented by flexibles with explicit prices for absorbing and
+// producing energy. Flexibles which do not implement Priced are treated as
+// having prices of zero.
+type Priced interface {
+	ChargingPrice() float64
+	DischargingPrice() float64
+}
+
+func chargingPrice(f Flexible) float64 {
+	if p, ok := f.(Priced); ok {
+		return p.ChargingPrice()
+	}
+
+	return 0
+}
+
+func dischargingPrice(f Flexible) float64 {
+	if p, ok := f.(Priced); ok {
+		return p.DischargingPrice()
+	}
+
+	return 0
+}
+
+// ChargeList is a list of flexibles sorted so that those willing to pay the
+// most for energy come first. Implements sort.Interface.
+type ChargeList []Flexible
+
+func (cl ChargeList) Len() int {
+	return len(cl)
+}
+
+func (cl ChargeList) Swap(i, j int) {
+	cl[i], cl[j] = cl[j], cl[i]
+}
+
+func (cl ChargeList) Less(i, j int) bool {
+	return chargingPrice(cl[i]) > chargingPrice(cl[j])
+}
+
+// DischargeList is a list of flexibles sorted so that those producing energy
+// at the lowest price come first. Implements sort.Interface.
+type DischargeList []Flexible
+
+func (dl DischargeList) Len() int {
+	return len(dl)
+}
+
+func (dl DischargeList) Swap(i, j int) {
+	dl[i], dl[j] = dl[j], dl[i]
+}
+
+func (dl DischargeList) Less(i, j int) bool {
+	return dischargingPrice(dl[i]) < dischargingPrice(dl[j])
+}
+
+// sortFlexibles returns the flexibles in the order in which they absorb excess
+// energy, and the order in which they discharge. Flexibles with equal prices
+// keep the order in which they were added.
+func sortFlexibles(flexibles []Flexible) (ChargeList, DischargeList) {
+	charging := make(ChargeList, len(flexibles))
+	discharging := make(DischargeList, len(flexibles))
+
+	copy(charging, flexibles)
+	copy(discharging, flexibles)
+
+	sort.Stable(charging)
+	sort.Stable(discharging)
+
+	return charging, discharging
+}
+
+// chargeList returns the flexibles in the order in which they absorb excess.
+func (o *Order) chargeList() []Flexible {
+	if o.charging == nil {
+		return o.Flexibles
+	}
+
+	return o.charging
+}
+
+// dischargeList returns the flexibles in the order in which they discharge.
+func (o *Order) dischargeList() []Flexible {
+	if o.discharging == nil {
+		return o.Flexibles
+	}
+
+	return o.discharging
+}
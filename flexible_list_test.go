@@ -0,0 +1,99 @@
+package merit
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestChargeListSort(t *testing.T) {
+	f1 := Flex{Key: "f1", ChargePrice: 1.0}
+	f2 := Flex{Key: "f2", ChargePrice: 3.0}
+	f3 := Flex{Key: "f3", ChargePrice: 2.0}
+
+	list := ChargeList{&f1, &f2, &f3}
+	sort.Sort(list)
+
+	expected := []*Flex{&f2, &f3, &f1}
+
+	for i, flex := range list {
+		if expected[i] != flex {
+			t.Errorf("Sorted ChargeList[%d] = %s, want %s",
+				i, flex.(*Flex).Key, expected[i].Key)
+		}
+	}
+}
+
+func TestDischargeListSort(t *testing.T) {
+	f1 := Flex{Key: "f1", DischargePrice: 3.0}
+	f2 := Flex{Key: "f2", DischargePrice: 1.0}
+	f3 := Flex{Key: "f3", DischargePrice: 2.0}
+
+	list := DischargeList{&f1, &f2, &f3}
+	sort.Sort(list)
+
+	expected := []*Flex{&f2, &f3, &f1}
+
+	for i, flex := range list {
+		if expected[i] != flex {
+			t.Errorf("Sorted DischargeList[%d] = %s, want %s",
+				i, flex.(*Flex).Key, expected[i].Key)
+		}
+	}
+}
+
+// Asserts that the order in which flexibles absorb excess is independent of the
+// order in which they discharge.
+func TestCalculateFlexiblePriorities(t *testing.T) {
+	battery := Storage{
+		Flex: Flex{
+			Key:            "battery",
+			Capacity:       1.0,
+			Units:          1.0,
+			ChargePrice:    1.0,
+			DischargePrice: 1.0,
+		},
+		reserve: NewReserveWithoutDecay(10.0),
+	}
+
+	heat := Storage{
+		Flex: Flex{
+			Key:            "heat",
+			Capacity:       1.0,
+			Units:          1.0,
+			ChargePrice:    2.0,
+			DischargePrice: 2.0,
+		},
+		reserve: NewReserveWithoutDecay(10.0),
+	}
+
+	ao := AlwaysOn{Profile: [8760]float64{3.0, 0.0}, TotalProduction: 1.0}
+	cons := Consumer{Profile: [8760]float64{1.5, 1.5}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddAlwaysOn(&ao)
+	order.AddStorage(&battery)
+	order.AddStorage(&heat)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame                 int
+		wantBattery, wantHeat float64
+	}{
+		{0, -0.5, -1.0}, // Heat absorbs first.
+		{1, 0.5, 1.0},   // Battery discharges first.
+	}
+
+	for _, test := range tests {
+		if load := battery.LoadAt(test.frame); load != test.wantBattery {
+			t.Errorf("Calculate assigned battery load %d = %f, want %f",
+				test.frame, load, test.wantBattery)
+		}
+
+		if load := heat.LoadAt(test.frame); load != test.wantHeat {
+			t.Errorf("Calculate assigned heat load %d = %f, want %f",
+				test.frame, load, test.wantHeat)
+		}
+	}
+}
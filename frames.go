@@ -0,0 +1,97 @@
+package merit
+
+import "iter"
+
+// ParticipantLoad is the load of a participant in a frame.
+type ParticipantLoad struct {
+	Participant Participant
+	Key         string
+	Load        float64
+}
+
+// FrameResult is the result of calculating a frame: the demand, the price and
+// the participant which set it, and the load of every participant. Loads are
+// listed in the same order in every frame: consumers and other demands,
+// always-ons and other producers, flexibles, dispatchables, suppliers, imports
+// and exports.
+type FrameResult struct {
+	Frame       int
+	Demand      float64
+	PriceSetter Participant
+	Price       float64
+	Loads       []ParticipantLoad
+}
+
+// Frames returns a sequence which calculates the order one frame at a time,
+// yielding the result of each as soon as it is computed, with a nil error.
+// Ending the iteration early stops the calculation. An error from an Observer
+// of the order also stops it, and is yielded along with the frame in which it
+// occurred.
+func Frames(order Order) iter.Seq2[FrameResult, error] {
+	return func(yield func(FrameResult, error) bool) {
+		prepare(&order)
+
+		// Recalculate cannot know how many frames were calculated.
+		order.tracker.forget()
+
+		participants := order.participants()
+		keys := make([]string, len(participants))
+
+		for i, p := range participants {
+			keys[i] = nameOf(p)
+		}
+
+		for frame := 0; frame < 8760; frame++ {
+			if err := calculateFrame(frame, order); err != nil {
+				yield(FrameResult{Frame: frame}, err)
+				return
+			}
+
+			result := FrameResult{
+				Frame:       frame,
+				Demand:      order.DemandAt(frame),
+				PriceSetter: order.PriceSetters[frame],
+				Price:       order.PriceAt(frame),
+				Loads:       make([]ParticipantLoad, len(participants)),
+			}
+
+			for i, p := range participants {
+				result.Loads[i] = ParticipantLoad{Participant: p, Key: keys[i], Load: p.LoadAt(frame)}
+			}
+
+			if !yield(result, nil) {
+				return
+			}
+		}
+	}
+}
+
+// participants returns every participant in the order.
+func (o *Order) participants() []Participant {
+	var list []Participant
+
+	list = append(list, o.demands()...)
+	list = append(list, o.producers()...)
+
+	for _, flex := range o.Flexibles {
+		list = append(list, flex)
+	}
+
+	for _, producer := range o.Dispatchables {
+		list = append(list, producer)
+	}
+
+	for _, supplier := range o.Suppliers {
+		list = append(list, supplier)
+	}
+
+	for _, imp := range o.Imports {
+		list = append(list, imp)
+	}
+
+	for _, export := range o.Exports {
+		list = append(list, export)
+	}
+
+	return list
+}
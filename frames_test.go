@@ -0,0 +1,117 @@
+package merit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFrames(t *testing.T) {
+	disp := Dispatchable{Key: "coal", Cost: 2.0, Capacity: 10.0, Units: 1.0}
+	cons := Consumer{Key: "household", Profile: [8760]float64{1.0, 3.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+
+	var count int
+
+	for result, err := range Frames(order) {
+		if err != nil {
+			t.Fatalf("Frames yielded an error in frame %d: %s", result.Frame, err)
+		}
+
+		if result.Frame != count {
+			t.Fatalf("Frames yielded frame %d, want %d", result.Frame, count)
+		}
+
+		count++
+
+		if result.Frame != 1 {
+			continue
+		}
+
+		if result.Demand != 3.0 || result.Price != 2.0 || result.PriceSetter != &disp {
+			t.Errorf("Frames yielded %+v for frame 1, want demand 3 priced by coal", result)
+		}
+
+		want := []ParticipantLoad{
+			{&cons, "household", 3.0},
+			{&disp, "coal", 3.0},
+		}
+
+		if len(result.Loads) != len(want) {
+			t.Fatalf("Frames yielded %d loads, want %d", len(result.Loads), len(want))
+		}
+
+		for i, load := range result.Loads {
+			if load != want[i] {
+				t.Errorf("Loads[%d] = %+v, want %+v", i, load, want[i])
+			}
+		}
+	}
+
+	if count != 8760 {
+		t.Errorf("Frames yielded %d frames, want 8760", count)
+	}
+}
+
+// Asserts that breaking from the loop stops the calculation.
+func TestFramesStopsEarly(t *testing.T) {
+	disp := Dispatchable{Capacity: 10.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0, 1.0, 1.0}, TotalDemand: 1.0})
+	order.AddDispatchable(&disp)
+
+	for result := range Frames(order) {
+		if result.Frame == 1 {
+			break
+		}
+	}
+
+	if load := disp.LoadAt(2); load != 0.0 {
+		t.Errorf("LoadAt(2) = %f, want frame 2 not calculated", load)
+	}
+}
+
+// Asserts that an error from an observer stops the calculation and is yielded.
+func TestFramesObserverError(t *testing.T) {
+	disp := Dispatchable{Capacity: 10.0, Units: 1.0}
+	stop := errors.New("stop")
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0, 1.0, 1.0}, TotalDemand: 1.0})
+	order.AddDispatchable(&disp)
+	order.AddObserver(ObserverFuncs{
+		Frame: func(balance FrameBalance) error {
+			if balance.Frame == 1 {
+				return stop
+			}
+
+			return nil
+		},
+	})
+
+	var count int
+	var got error
+
+	for result, err := range Frames(order) {
+		count++
+
+		if err != nil {
+			got = err
+
+			if result.Frame != 1 {
+				t.Errorf("Frames yielded the error in frame %d, want 1", result.Frame)
+			}
+		}
+	}
+
+	if got != stop {
+		t.Errorf("Frames yielded error %v, want %v", got, stop)
+	}
+
+	if count != 2 {
+		t.Errorf("Frames yielded %d results, want 2", count)
+	}
+}
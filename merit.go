@@ -1,7 +1,93 @@
 // Package merit computes ranks sources of energy by to cost and assigns load
 // to producers accordingly.
+//
+// Orders accept participants of types defined outside this package, allowing
+// other technologies to take part in the calculation: any Participant as
+// demand or always-on production, and any Flexible, Bidder or Supplier.
 package merit
 
-type participant interface {
+// Participant is implemented by everything which takes part in the merit
+// order. LoadAt returns the energy produced or used by the participant in a
+// frame.
+type Participant interface {
 	LoadAt(int) float64
 }
+
+// Flexible is implemented by participants which may both absorb excess energy
+// and later produce energy, such as Flex and Storage.
+//
+// AssignExcessAt offers excess energy to the participant, returning the amount
+// it absorbed. AvailableAt returns how much energy the participant may produce
+// in a frame, and SetLoadAt tells the participant how much it is to produce.
+// A Flexible which records the outcome of frames should implement Forgetter.
+type Flexible interface {
+	Participant
+	AssignExcessAt(int, float64) float64
+	SetLoadAt(int, float64) error
+	AvailableAt(int) float64
+}
+
+// Bidder is implemented by demand which is only met while the price of energy
+// does not exceed what it is willing to pay, like a Consumer with Segments.
+//
+// BidsAt returns the price-sensitive parts of the demand in a frame; the
+// Participant of each Bid is ignored. It is called once each time the frame is
+// calculated, before Shed is called with each amount of demand which was not
+// met, so a Bidder should forget what was shed in the frame when it is called.
+type Bidder interface {
+	Participant
+	BidsAt(frame int) []Bid
+	Shed(frame int, amount float64)
+}
+
+// Supplier is implemented by producers which, like Dispatchable, offer their
+// capacity at a cost and produce only when it is used.
+//
+// OffersAt returns the capacity offered in a frame; the Participant of each Bid
+// is ignored. SetLoadAt tells the supplier how much it is to produce. Its load
+// is set to zero before each calculation of a frame.
+type Supplier interface {
+	Participant
+	OffersAt(frame int) []Bid
+	SetLoadAt(int, float64) error
+}
+
+// Forgetter may be implemented by a Flexible, Bidder or Supplier of a type
+// defined outside this package which records the outcome of frames. Forget is
+// called with frames start to end (exclusive) before they are calculated
+// again: with each frame as it is calculated, and with every frame of a window
+// or recalculation.
+type Forgetter interface {
+	Forget(start, end int)
+}
+
+// resetter is implemented by participants which record the outcome of a frame,
+// and must forget it before the frame is calculated again.
+type resetter interface {
+	resetAt(frame int)
+}
+
+// KeyOf returns the Key of a participant of a type defined in this package, or
+// an empty string when the participant has no key.
+func KeyOf(p Participant) string {
+	switch p := p.(type) {
+	case *AlwaysOn:
+		return p.Key
+	case *Consumer:
+		return p.Key
+	case *Dispatchable:
+		return p.Key
+	case *Import:
+		return p.Key
+	case *Flex:
+		return p.Key
+	case *Storage:
+		return p.Key
+	case *Sink:
+		return p.Key
+	case *Export:
+		return p.Key
+	}
+
+	return ""
+}
@@ -0,0 +1,226 @@
+package merit
+
+import "testing"
+
+// fixedDemand is a participant which uses the same amount of energy in every
+// frame.
+type fixedDemand float64
+
+func (d fixedDemand) LoadAt(frame int) float64 {
+	return float64(d)
+}
+
+// fixedProducer is a participant with the same production in every frame.
+type fixedProducer float64
+
+func (p fixedProducer) LoadAt(frame int) float64 {
+	return float64(p)
+}
+
+// flexibleDemand is a Bidder which uses a fixed amount of energy in every
+// frame, of which part is bid at a price.
+type flexibleDemand struct {
+	demand, flexible, price float64
+	shed                    [8760]float64
+}
+
+func (d *flexibleDemand) LoadAt(frame int) float64 {
+	return d.demand
+}
+
+func (d *flexibleDemand) BidsAt(frame int) []Bid {
+	d.shed[frame] = 0
+	return []Bid{{Price: d.price, Volume: d.flexible}}
+}
+
+func (d *flexibleDemand) Shed(frame int, amount float64) {
+	d.shed[frame] += amount
+}
+
+// generator is a Supplier which offers a fixed capacity at a cost in every
+// frame.
+type generator struct {
+	capacity, cost float64
+	load           [8760]float64
+}
+
+func (g *generator) LoadAt(frame int) float64 {
+	return g.load[frame]
+}
+
+func (g *generator) OffersAt(frame int) []Bid {
+	return []Bid{{Price: g.cost, Volume: g.capacity}}
+}
+
+func (g *generator) SetLoadAt(frame int, amount float64) error {
+	g.load[frame] = amount
+	return nil
+}
+
+// sponge is a Flexible which absorbs all excess energy offered to it, and
+// forgets what it absorbed when told to.
+type sponge struct {
+	load [8760]float64
+}
+
+func (s *sponge) LoadAt(frame int) float64 {
+	return s.load[frame]
+}
+
+func (s *sponge) AssignExcessAt(frame int, amount float64) float64 {
+	s.load[frame] -= amount
+	return amount
+}
+
+func (s *sponge) SetLoadAt(frame int, amount float64) error {
+	s.load[frame] = amount
+	return nil
+}
+
+func (s *sponge) AvailableAt(frame int) float64 {
+	return 0
+}
+
+func (s *sponge) Copy() Participant {
+	dup := *s
+	return &dup
+}
+
+func (s *sponge) Forget(start, end int) {
+	for frame := start; frame < end; frame++ {
+		s.load[frame] = 0
+	}
+}
+
+// fleet is a Flexible which may always discharge a fixed amount of energy, and
+// never absorbs excess.
+type fleet struct {
+	available float64
+	load      [8760]float64
+}
+
+func (f *fleet) LoadAt(frame int) float64 {
+	return f.load[frame]
+}
+
+func (f *fleet) AssignExcessAt(frame int, amount float64) float64 {
+	return 0
+}
+
+func (f *fleet) SetLoadAt(frame int, amount float64) error {
+	f.load[frame] = amount
+	return nil
+}
+
+func (f *fleet) AvailableAt(frame int) float64 {
+	return f.available
+}
+
+func TestCalculateCustomParticipants(t *testing.T) {
+	custom := &fleet{available: 1.0}
+	disp := Dispatchable{Capacity: 5.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddDemand(fixedDemand(3.0))
+	order.AddProducer(fixedProducer(0.5))
+	order.AddFlexible(custom)
+	order.AddDispatchable(&disp)
+
+	Calculate(order)
+
+	if load := custom.LoadAt(0); load != 1.0 {
+		t.Errorf("Calculate assigned custom flexible load %f, want 1.0", load)
+	}
+
+	if load := disp.LoadAt(0); load != 1.5 {
+		t.Errorf("Calculate assigned dispatchable load %f, want 1.5", load)
+	}
+}
+
+// Asserts that the price-sensitive part of custom demand is shed when energy
+// is more expensive than its bid.
+func TestCalculateCustomBidder(t *testing.T) {
+	demand := &flexibleDemand{demand: 3.0, flexible: 1.0, price: 5.0}
+	disp := Dispatchable{Cost: 10.0, Capacity: 5.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddDemand(demand)
+	order.AddDispatchable(&disp)
+
+	for i := 0; i < 2; i++ {
+		Calculate(order)
+
+		if shed := demand.shed[0]; shed != 1.0 {
+			t.Errorf("Calculate %d shed %f of custom demand, want 1.0", i+1, shed)
+		}
+	}
+
+	if load := disp.LoadAt(0); load != 2.0 {
+		t.Errorf("Calculate assigned dispatchable load %f, want 2.0", load)
+	}
+
+	if clearing := Clear(order)[0]; demand.shed[0] != 1.0 || clearing.Price != 10.0 {
+		t.Errorf("Clear shed %f at %f, want 1.0 at 10.0", demand.shed[0], clearing.Price)
+	}
+}
+
+// Asserts that a custom supplier is used in order of its cost, and may set the
+// price.
+func TestCalculateCustomSupplier(t *testing.T) {
+	custom := &generator{capacity: 2.0, cost: 1.0}
+	disp := Dispatchable{Cost: 5.0, Capacity: 5.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddDemand(fixedDemand(3.0))
+	order.AddSupplier(custom)
+	order.AddDispatchable(&disp)
+
+	Calculate(order)
+
+	if load := custom.LoadAt(0); load != 2.0 {
+		t.Errorf("Calculate assigned custom supplier load %f, want 2.0", load)
+	}
+
+	if load := disp.LoadAt(0); load != 1.0 {
+		t.Errorf("Calculate assigned dispatchable load %f, want 1.0", load)
+	}
+
+	disp.Capacity = 0.0
+	custom.capacity = 4.0
+	Calculate(order)
+
+	if setter, price := order.PriceSetters[0], order.PriceAt(0); setter != custom || price != 1.0 {
+		t.Errorf("PriceSetters[0], PriceAt(0) = %v, %f, want the supplier at 1.0", setter, price)
+	}
+
+	if load := custom.LoadAt(0); load != 3.0 {
+		t.Errorf("Calculate assigned custom supplier load %f, want 3.0", load)
+	}
+}
+
+// Asserts that a custom flexible which implements Forgetter absorbs the same
+// excess each time the order is calculated.
+func TestCalculateCustomForgetter(t *testing.T) {
+	custom := &sponge{}
+
+	order := NewOrder()
+	order.AddDemand(fixedDemand(1.0))
+	order.AddProducer(fixedProducer(3.0))
+	order.AddFlexible(custom)
+
+	for i := 0; i < 2; i++ {
+		Calculate(order)
+
+		if load := custom.LoadAt(0); load != -2.0 {
+			t.Errorf("Calculate %d assigned custom flexible load %f, want -2.0", i+1, load)
+		}
+	}
+
+	if err := CalculateWindow(order, Window{Start: 0, End: 1}); err != nil {
+		t.Fatalf("CalculateWindow returned an error: %s", err)
+	}
+
+	if load := custom.LoadAt(0); load != -2.0 {
+		t.Errorf("CalculateWindow assigned custom flexible load %f, want -2.0", load)
+	}
+}
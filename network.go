@@ -0,0 +1,368 @@
+package merit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Region is a merit order which forms part of a Network.
+type Region struct {
+	Key   string
+	Order Order
+}
+
+// Interconnector links two regions in a Network, allowing dispatchables in one
+// region to meet demand in the other. Capacity is the amount of energy which
+// may be sent from From to To in each frame, and ReverseCapacity the amount
+// which may be sent from To to From. Loss is the share of energy sent which
+// is lost in transit, from zero up to but not including one.
+type Interconnector struct {
+	Key             string
+	From            *Region
+	To              *Region
+	Capacity        [8760]float64
+	ReverseCapacity [8760]float64
+	Loss            float64
+	flow            [8760]float64
+}
+
+// FlowAt returns the energy sent through the interconnector in frame, before
+// losses. Positive values flow from From to To, and negative values from To
+// to From.
+func (ic *Interconnector) FlowAt(frame int) float64 {
+	return ic.flow[frame]
+}
+
+// CongestedAt returns whether all the capacity of the interconnector, in the
+// direction in which energy flowed, was used in frame.
+func (ic *Interconnector) CongestedAt(frame int) bool {
+	flow := ic.flow[frame]
+
+	switch {
+	case flow > 0:
+		return flow >= ic.Capacity[frame]
+	case flow < 0:
+		return -flow >= ic.ReverseCapacity[frame]
+	}
+
+	return false
+}
+
+// availableFrom returns how much more energy may be sent from the region
+// through the interconnector in frame. Energy never flows in both directions
+// in the same frame.
+func (ic *Interconnector) availableFrom(region *Region, frame int) float64 {
+	flow := ic.flow[frame]
+
+	if region == ic.From {
+		if flow < 0 {
+			return 0
+		}
+
+		return ic.Capacity[frame] - flow
+	}
+
+	if flow > 0 {
+		return 0
+	}
+
+	return ic.ReverseCapacity[frame] + flow
+}
+
+// sendFrom records that energy was sent from the region through the
+// interconnector in frame.
+func (ic *Interconnector) sendFrom(region *Region, frame int, amount float64) {
+	if region == ic.From {
+		ic.flow[frame] += amount
+	} else {
+		ic.flow[frame] -= amount
+	}
+}
+
+// Network is a group of regions connected by interconnectors, whose merit
+// orders are calculated jointly.
+type Network struct {
+	Regions         []*Region
+	Interconnectors []*Interconnector
+}
+
+// CalculateNetwork receives a network and computes the merit order of every
+// region in each frame.
+//
+// Demand in each region is first met by the stages of its own merit order,
+// other than the DispatchableStage. Dispatchables in every region are then
+// used in order of the cost of delivering their energy - their own cost plus
+// any losses on the interconnector - until demand in each region is met, or
+// interconnectors are congested. Energy may only be sent through one
+// interconnector; transit through a third region is not modelled.
+//
+// The ProRata and Observers of each region are ignored: dispatchables whose
+// energy costs the same to deliver are used one after another, and observers
+// are not told of the frames calculated.
+//
+// An error is returned, and nothing is calculated, when the Loss of an
+// interconnector is outside its range, or it links a region which is not part
+// of the network.
+func CalculateNetwork(network Network) error {
+	for _, link := range network.Interconnectors {
+		if link.Loss < 0 || link.Loss >= 1 {
+			return fmt.Errorf(
+				"CalculateNetwork: Interconnector %q has invalid loss %f",
+				link.Key, link.Loss)
+		}
+
+		if !containsRegion(network.Regions, link.From) ||
+			!containsRegion(network.Regions, link.To) {
+			return fmt.Errorf(
+				"CalculateNetwork: Interconnector %q links a region outside the network",
+				link.Key)
+		}
+	}
+
+	for _, region := range network.Regions {
+		prepare(&region.Order)
+		region.Order.tracker.forget()
+	}
+
+	for frame := 0; frame < 8760; frame++ {
+		calculateNetworkFrame(frame, network)
+	}
+
+	return nil
+}
+
+// regionFrame holds the state of a region while a frame is being calculated.
+type regionFrame struct {
+	region    *Region
+	remaining float64
+	excess    float64
+	bids      shedder
+	done      bool
+
+	// marginal is the most expensive producer to have delivered to the region,
+	// or the price setter of an earlier stage. It sets the price if demand
+	// cannot be met, or the last of the demand is shed without buying
+	// anything.
+	marginal     Participant
+	marginalCost float64
+}
+
+// finish records the price setter and price of the region in frame. No more
+// demand is assigned to the region.
+func (rf *regionFrame) finish(frame int, setter Participant, price float64) {
+	rf.region.Order.setPriceAt(frame, setter, price)
+	rf.done = true
+}
+
+// finishMet records the price of a region whose demand was met without a bid
+// setting the price: that of the marginal producer, or the cheapest offer in
+// the region when nothing was delivered.
+func (rf *regionFrame) finishMet(frame int) {
+	if rf.marginal != nil {
+		rf.finish(frame, rf.marginal, rf.marginalCost)
+	} else if offers := rf.region.Order.offersAt(frame); len(offers) > 0 {
+		rf.finish(frame, offers[0].producer, offers[0].cost)
+	} else {
+		rf.finish(frame, nil, 0)
+	}
+}
+
+// networkOffer is an offer from a dispatchable in a region, along with the
+// capacity which has not yet been used.
+type networkOffer struct {
+	offer
+	region    *Region
+	remaining float64
+}
+
+// delivery is a way in which an offer may meet demand in a region: either
+// directly when the offer is in the same region, or through an interconnector.
+type delivery struct {
+	offer *networkOffer
+	to    *regionFrame
+	link  *Interconnector
+	cost  float64
+}
+
+func calculateNetworkFrame(frame int, network Network) {
+	regions := make(map[*Region]*regionFrame, len(network.Regions))
+
+	for _, link := range network.Interconnectors {
+		link.flow[frame] = 0
+	}
+
+	for _, region := range network.Regions {
+		order := region.Order
+		order.resetAt(frame)
+
+		local := FrameState{
+			Frame:     frame,
+			Remaining: order.DemandAt(frame) + order.exportsAt(frame),
+		}
+
+		// Dispatchables are shared between regions, and are used below rather
+		// than by the stages of each region.
+		for _, stage := range order.stages() {
+			if _, ok := stage.(DispatchableStage); !ok {
+				stage.Run(order, &local)
+			}
+		}
+
+		state := &regionFrame{
+			region:    region,
+			remaining: local.Remaining,
+			excess:    local.Excess,
+			bids:      shedder{bids: order.bidsAt(frame)},
+		}
+
+		if local.PriceSetter != nil {
+			state.marginal, state.marginalCost = local.PriceSetter, local.Price
+		}
+
+		// Sinks buy energy from any delivery cheaper than their ChargePrice.
+		if charging, amount := order.chargeBidsAt(frame); amount > 0 {
+			state.bids.bids = append(state.bids.bids, charging...)
+			sort.Stable(state.bids.bids)
+
+			state.remaining += amount
+		}
+
+		if state.remaining <= 0 {
+			state.finishMet(frame)
+		}
+
+		regions[region] = state
+	}
+
+	for _, delivery := range network.deliveries(frame, regions) {
+		state := delivery.to
+		offer := delivery.offer
+
+		if state.done || offer.remaining <= 0 {
+			continue
+		}
+
+		shed, last := state.bids.shedBelow(frame, delivery.cost, state.remaining)
+
+		if shed > 0 {
+			state.remaining -= shed
+
+			if state.remaining <= 0 {
+				state.finish(frame, last.owner, last.price)
+				continue
+			}
+		}
+
+		efficiency := 1.0
+		available := offer.remaining
+
+		if delivery.link != nil {
+			efficiency = 1 - delivery.link.Loss
+			available = math.Min(
+				available, delivery.link.availableFrom(offer.region, frame))
+		}
+
+		if available <= 0 {
+			continue
+		}
+
+		sent := state.remaining / efficiency
+
+		if available < sent {
+			sent = available
+			state.remaining -= sent * efficiency
+		} else {
+			state.remaining = 0
+		}
+
+		addLoad(offer.producer, frame, sent)
+		offer.remaining -= sent
+
+		state.marginal, state.marginalCost = offer.producer, delivery.cost
+
+		if delivery.link != nil {
+			delivery.link.sendFrom(offer.region, frame, sent)
+		}
+
+		if state.remaining <= 0 {
+			state.finish(frame, offer.producer, delivery.cost)
+		}
+	}
+
+	// There is insufficient capacity to meet demand in some regions;
+	// price-sensitive demand is shed before any other demand goes unmet.
+	for _, state := range regions {
+		if !state.done {
+			shed, last := state.bids.shedBelow(frame, math.Inf(1), state.remaining)
+			state.remaining -= shed
+
+			if shed > 0 && state.remaining <= 0 && last.amount > 0 {
+				state.finish(frame, last.owner, last.price)
+			} else if state.remaining <= 0 {
+				state.finishMet(frame)
+			} else {
+				setter, price := state.region.Order.scarcityPrice(
+					state.marginal, state.marginalCost)
+
+				state.finish(frame, setter, price)
+			}
+		}
+
+		state.region.Order.setUnmetAt(frame, state.remaining, state.excess)
+	}
+}
+
+// deliveries returns every way in which the offers of dispatchables and imports
+// may meet demand in the regions in frame, sorted by the cost of delivery.
+func (n *Network) deliveries(frame int, regions map[*Region]*regionFrame) []delivery {
+	var deliveries []delivery
+
+	for _, region := range n.Regions {
+		for _, o := range region.Order.offersAt(frame) {
+			offer := &networkOffer{offer: o, region: region, remaining: o.capacity}
+
+			deliveries = append(deliveries, delivery{
+				offer: offer,
+				to:    regions[region],
+				cost:  o.cost,
+			})
+
+			for _, link := range n.Interconnectors {
+				var to *Region
+
+				switch region {
+				case link.From:
+					to = link.To
+				case link.To:
+					to = link.From
+				default:
+					continue
+				}
+
+				deliveries = append(deliveries, delivery{
+					offer: offer,
+					to:    regions[to],
+					link:  link,
+					cost:  o.cost / (1 - link.Loss),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].cost < deliveries[j].cost
+	})
+
+	return deliveries
+}
+
+func containsRegion(regions []*Region, region *Region) bool {
+	for _, r := range regions {
+		if r == region {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package merit
+
+import "testing"
+
+func TestCalculateNetwork(t *testing.T) {
+	cheap := Dispatchable{Key: "cheap", Cost: 1.0, Capacity: 10.0, Units: 1.0}
+	dear := Dispatchable{Key: "dear", Cost: 5.0, Capacity: 10.0, Units: 1.0}
+
+	consA := Consumer{Profile: [8760]float64{5.0, 2.0}, TotalDemand: 1.0}
+	consB := Consumer{Profile: [8760]float64{8.0, 4.0}, TotalDemand: 1.0}
+
+	regionA := &Region{Key: "a", Order: NewOrder()}
+	regionA.Order.AddConsumer(&consA)
+	regionA.Order.AddDispatchable(&cheap)
+
+	regionB := &Region{Key: "b", Order: NewOrder()}
+	regionB.Order.AddConsumer(&consB)
+	regionB.Order.AddDispatchable(&dear)
+
+	link := &Interconnector{
+		From:     regionA,
+		To:       regionB,
+		Capacity: [8760]float64{3.0, 10.0},
+		Loss:     0.2,
+	}
+
+	err := CalculateNetwork(Network{
+		Regions:         []*Region{regionA, regionB},
+		Interconnectors: []*Interconnector{link},
+	})
+
+	if err != nil {
+		t.Fatalf("CalculateNetwork returned an error: %s", err)
+	}
+
+	tests := []struct {
+		frame               int
+		wantCheap, wantDear float64
+		wantFlow            float64
+		wantCongested       bool
+		wantPriceA          float64
+		wantPriceB          float64
+		wantSetterB         Participant
+	}{
+		{0, 8.0, 5.6, 3.0, true, 1.0, 5.0, &dear},
+		{1, 7.0, 0.0, 5.0, false, 1.0, 1.25, &cheap},
+	}
+
+	for _, test := range tests {
+		if load := cheap.LoadAt(test.frame); toFixed(load, 10) != test.wantCheap {
+			t.Errorf("CalculateNetwork assigned cheap load %d = %f, want %f",
+				test.frame, load, test.wantCheap)
+		}
+
+		if load := dear.LoadAt(test.frame); toFixed(load, 10) != test.wantDear {
+			t.Errorf("CalculateNetwork assigned dear load %d = %f, want %f",
+				test.frame, load, test.wantDear)
+		}
+
+		if flow := link.FlowAt(test.frame); toFixed(flow, 10) != test.wantFlow {
+			t.Errorf("Interconnector.FlowAt(%d) = %f, want %f",
+				test.frame, flow, test.wantFlow)
+		}
+
+		if congested := link.CongestedAt(test.frame); congested != test.wantCongested {
+			t.Errorf("Interconnector.CongestedAt(%d) = %t, want %t",
+				test.frame, congested, test.wantCongested)
+		}
+
+		if price := regionA.Order.PriceAt(test.frame); price != test.wantPriceA {
+			t.Errorf("Region A price in frame %d = %f, want %f",
+				test.frame, price, test.wantPriceA)
+		}
+
+		if price := regionB.Order.PriceAt(test.frame); price != test.wantPriceB {
+			t.Errorf("Region B price in frame %d = %f, want %f",
+				test.frame, price, test.wantPriceB)
+		}
+
+		if setter := regionB.Order.PriceSetters[test.frame]; setter != test.wantSetterB {
+			t.Errorf("Region B has the wrong price setter in frame %d",
+				test.frame)
+		}
+	}
+}
+
+// Asserts that a network is not calculated when an interconnector would lose
+// all of the energy sent through it, or create energy.
+func TestCalculateNetworkInvalidLoss(t *testing.T) {
+	for _, loss := range []float64{1.0, 1.5, -0.1} {
+		disp := Dispatchable{Cost: 1.0, Capacity: 10.0, Units: 1.0}
+
+		regionA := &Region{Key: "a", Order: NewOrder()}
+		regionA.Order.AddDispatchable(&disp)
+
+		regionB := &Region{Key: "b", Order: NewOrder()}
+		regionB.Order.AddConsumer(&Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0})
+
+		link := &Interconnector{
+			From:     regionA,
+			To:       regionB,
+			Capacity: [8760]float64{10.0},
+			Loss:     loss,
+		}
+
+		err := CalculateNetwork(Network{
+			Regions:         []*Region{regionA, regionB},
+			Interconnectors: []*Interconnector{link},
+		})
+
+		if err == nil {
+			t.Errorf("CalculateNetwork with a loss of %f returned no error", loss)
+		}
+
+		if load := disp.LoadAt(0); load != 0.0 || link.FlowAt(0) != 0.0 {
+			t.Errorf("CalculateNetwork with a loss of %f sent %f", loss, link.FlowAt(0))
+		}
+	}
+}
+
+// Asserts that a network is not calculated when an interconnector links a
+// region outside the network.
+func TestCalculateNetworkUnknownRegion(t *testing.T) {
+	regionA := &Region{Key: "a", Order: NewOrder()}
+	regionA.Order.AddDispatchable(&Dispatchable{Cost: 1.0, Capacity: 10.0, Units: 1.0})
+
+	links := []*Interconnector{
+		{Key: "to", From: regionA, To: &Region{Key: "b", Order: NewOrder()}},
+		{Key: "from", From: &Region{Key: "b", Order: NewOrder()}, To: regionA},
+		{Key: "nil", From: regionA},
+	}
+
+	for _, link := range links {
+		err := CalculateNetwork(Network{
+			Regions:         []*Region{regionA},
+			Interconnectors: []*Interconnector{link},
+		})
+
+		if err == nil {
+			t.Errorf("CalculateNetwork with link %q returned no error", link.Key)
+		}
+	}
+}
+
+func TestInterconnectorAvailableFrom(t *testing.T) {
+	from, to := &Region{}, &Region{}
+
+	link := Interconnector{
+		From:            from,
+		To:              to,
+		Capacity:        [8760]float64{5.0},
+		ReverseCapacity: [8760]float64{2.0},
+	}
+
+	if available := link.availableFrom(from, 0); available != 5.0 {
+		t.Errorf("Interconnector.availableFrom(from) = %f, want 5.0", available)
+	}
+
+	link.sendFrom(to, 0, 1.5)
+
+	if available := link.availableFrom(to, 0); available != 0.5 {
+		t.Errorf("Interconnector.availableFrom(to) = %f, want 0.5", available)
+	}
+
+	// Energy may not flow in both directions at once.
+	if available := link.availableFrom(from, 0); available != 0.0 {
+		t.Errorf("Interconnector.availableFrom(from) = %f, want 0.0", available)
+	}
+}
@@ -0,0 +1,56 @@
+package merit
+
+// Observer is notified as an order is calculated: after each step of a frame,
+// such as load being assigned to a participant, and after each frame has been
+// calculated. Returning an error stops the calculation once the frame has been
+// completed, and the error is returned by Calculate.
+//
+// Observers of an order calculated with CalculateParallel are called from
+// several goroutines at once.
+type Observer interface {
+	ObserveStep(frame int, step Step) error
+	ObserveFrame(balance FrameBalance) error
+}
+
+// ObserverFuncs is an Observer which calls its functions. Either may be nil.
+type ObserverFuncs struct {
+	Step  func(frame int, step Step) error
+	Frame func(balance FrameBalance) error
+}
+
+// ObserveStep implements Observer.
+func (o ObserverFuncs) ObserveStep(frame int, step Step) error {
+	if o.Step == nil {
+		return nil
+	}
+
+	return o.Step(frame, step)
+}
+
+// ObserveFrame implements Observer.
+func (o ObserverFuncs) ObserveFrame(balance FrameBalance) error {
+	if o.Frame == nil {
+		return nil
+	}
+
+	return o.Frame(balance)
+}
+
+// AddObserver adds an Observer to be notified as the order is calculated.
+func (o *Order) AddObserver(observer Observer) {
+	o.Observers = append(o.Observers, observer)
+}
+
+// stepRecorder is an Observer which keeps each step of a frame.
+type stepRecorder struct {
+	steps []Step
+}
+
+func (r *stepRecorder) ObserveStep(frame int, step Step) error {
+	r.steps = append(r.steps, step)
+	return nil
+}
+
+func (r *stepRecorder) ObserveFrame(balance FrameBalance) error {
+	return nil
+}
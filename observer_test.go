@@ -0,0 +1,131 @@
+package merit
+
+import (
+	"errors"
+	"testing"
+)
+
+func observedOrder() (Order, *Dispatchable) {
+	disp := &Dispatchable{Key: "coal", Cost: 1.0, Capacity: 10.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0, 2.0, 3.0, 4.0}, TotalDemand: 1.0})
+	order.AddDispatchable(disp)
+
+	return order, disp
+}
+
+func TestObserverNotified(t *testing.T) {
+	order, _ := observedOrder()
+
+	var frames int
+	var dispatched float64
+
+	order.AddObserver(ObserverFuncs{
+		Step: func(frame int, step Step) error {
+			if step.Kind == StepDispatchable && step.Key == "coal" {
+				dispatched += step.Amount
+			}
+
+			return nil
+		},
+		Frame: func(balance FrameBalance) error {
+			frames++
+			return nil
+		},
+	})
+
+	if err := Calculate(order); err != nil {
+		t.Fatalf("Calculate returned an error: %s", err)
+	}
+
+	if frames != 8760 {
+		t.Errorf("ObserveFrame called %d times, want 8760", frames)
+	}
+
+	if dispatched != 10.0 {
+		t.Errorf("ObserveStep saw %f dispatched, want 10.0", dispatched)
+	}
+}
+
+// Asserts that an observer returning an error stops the calculation after the
+// frame is completed.
+func TestObserverAborts(t *testing.T) {
+	order, disp := observedOrder()
+	anomaly := errors.New("demand too high")
+
+	order.AddObserver(ObserverFuncs{
+		Frame: func(balance FrameBalance) error {
+			if balance.Demand > 2.5 {
+				return anomaly
+			}
+
+			return nil
+		},
+	})
+
+	if err := Calculate(order); err != anomaly {
+		t.Errorf("Calculate returned %v, want %v", err, anomaly)
+	}
+
+	if load := disp.LoadAt(2); load != 3.0 {
+		t.Errorf("LoadAt(2) = %f, want the frame to be completed", load)
+	}
+
+	if load := disp.LoadAt(3); load != 0.0 {
+		t.Errorf("LoadAt(3) = %f, want no more frames calculated", load)
+	}
+}
+
+// Asserts that observers are no longer notified once one returns an error.
+func TestObserverAbortsStep(t *testing.T) {
+	order, disp := observedOrder()
+	anomaly := errors.New("unexpected step")
+
+	var frames int
+
+	order.AddObserver(ObserverFuncs{
+		Step: func(frame int, step Step) error {
+			if step.Kind == StepDispatchable {
+				return anomaly
+			}
+
+			return nil
+		},
+		Frame: func(balance FrameBalance) error {
+			frames++
+			return nil
+		},
+	})
+
+	if err := Calculate(order); err != anomaly {
+		t.Errorf("Calculate returned %v, want %v", err, anomaly)
+	}
+
+	if frames != 0 {
+		t.Errorf("ObserveFrame called %d times, want 0", frames)
+	}
+
+	if load := disp.LoadAt(1); load != 0.0 {
+		t.Errorf("LoadAt(1) = %f, want no more frames calculated", load)
+	}
+}
+
+func TestObserverAbortsParallel(t *testing.T) {
+	order, _ := observedOrder()
+	anomaly := errors.New("unexpected step")
+
+	order.AddObserver(ObserverFuncs{
+		Step: func(frame int, step Step) error {
+			if step.Kind == StepDispatchable {
+				return anomaly
+			}
+
+			return nil
+		},
+	})
+
+	if err := CalculateParallel(order, 4); err != anomaly {
+		t.Errorf("CalculateParallel returned %v, want %v", err, anomaly)
+	}
+}
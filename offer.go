@@ -0,0 +1,143 @@
+package merit
+
+import "math"
+
+// Block describes part of the capacity of a Dispatchable which is offered at a
+// different cost to the rest. Share is the fraction of the total capacity of
+// the dispatchable contained in the block. Blocks are filled in order; once
+// their shares reach the whole capacity, any further share is ignored.
+type Block struct {
+	Share float64
+	Cost  float64
+}
+
+// BlockList is the list of blocks of a Dispatchable.
+type BlockList []Block
+
+// supplier is implemented by participants which offer capacity to the merit
+// order, and are assigned load when it is used.
+type supplier interface {
+	Participant
+	addLoadAt(frame int, amount float64)
+}
+
+// addLoad increases the load of the producer of an offer in frame.
+func addLoad(producer Participant, frame int, amount float64) {
+	switch producer := producer.(type) {
+	case supplier:
+		producer.addLoadAt(frame, amount)
+	case Supplier:
+		producer.SetLoadAt(frame, producer.LoadAt(frame)+amount)
+	}
+}
+
+// supplierOffersAt returns the capacity offered by a Supplier in frame.
+func supplierOffersAt(s Supplier, frame int) []offer {
+	var offers []offer
+
+	for _, offered := range s.OffersAt(frame) {
+		offers = append(offers, offer{producer: s, cost: offered.Price, capacity: offered.Volume})
+	}
+
+	return offers
+}
+
+// offer is an amount of capacity which a dispatchable or import offers to the
+// merit order at a single cost. Each dispatchable makes one offer per block.
+type offer struct {
+	producer Participant
+	cost     float64
+	capacity float64
+}
+
+// offers returns the blocks of capacity offered by the dispatchable. A
+// dispatchable without blocks offers all of its capacity at Cost.
+func (d *Dispatchable) offers() []offer {
+	total := d.TotalCapacity()
+
+	if d.Blocks == nil || len(*d.Blocks) == 0 {
+		return []offer{{producer: d, cost: d.Cost, capacity: total}}
+	}
+
+	offers := make([]offer, 0, len(*d.Blocks)+1)
+	remaining := 1.0
+
+	for _, block := range *d.Blocks {
+		share := math.Min(block.Share, remaining)
+
+		if share <= 0 {
+			break
+		}
+
+		offers = append(offers, offer{
+			producer: d,
+			cost:     block.Cost,
+			capacity: share * total,
+		})
+
+		remaining -= share
+	}
+
+	if remaining > 0 {
+		offers = append(offers, offer{
+			producer: d,
+			cost:     d.Cost,
+			capacity: remaining * total,
+		})
+	}
+
+	return offers
+}
+
+// offerList is a list of offers sorted by their cost. Implements
+// sort.Interface.
+type offerList []offer
+
+func (ol offerList) Len() int {
+	return len(ol)
+}
+
+func (ol offerList) Swap(i, j int) {
+	ol[i], ol[j] = ol[j], ol[i]
+}
+
+func (ol offerList) Less(i, j int) bool {
+	return ol[i].cost < ol[j].cost
+}
+
+// offers returns the offers of every dispatchable in the list.
+func (dl DispatchableList) offers() offerList {
+	var offers offerList
+
+	for _, producer := range dl {
+		offers = append(offers, producer.offers()...)
+	}
+
+	return offers
+}
+
+// groupEnd returns the index after the last offer in the group starting at
+// start. When proRata is true, a group contains every consecutive offer with
+// the same cost; otherwise each offer is a group of its own.
+func (ol offerList) groupEnd(start int, proRata bool) int {
+	end := start + 1
+
+	if proRata {
+		for end < len(ol) && ol[end].cost == ol[start].cost {
+			end++
+		}
+	}
+
+	return end
+}
+
+// capacity returns the total capacity of the offers in the list.
+func (ol offerList) capacity() float64 {
+	var sum float64
+
+	for _, offer := range ol {
+		sum += offer.capacity
+	}
+
+	return sum
+}
@@ -0,0 +1,91 @@
+package merit
+
+import "testing"
+
+func TestDispatchableOffersWithoutBlocks(t *testing.T) {
+	disp := Dispatchable{Cost: 2.0, Capacity: 1.5, Units: 2.0}
+	offers := disp.offers()
+
+	if len(offers) != 1 {
+		t.Fatalf("Dispatchable.offers() returned %d offers, want 1",
+			len(offers))
+	}
+
+	if offers[0].cost != 2.0 || offers[0].capacity != 3.0 {
+		t.Errorf("Dispatchable.offers()[0] = {Cost: %f, Capacity: %f}, "+
+			"want {Cost: 2.0, Capacity: 3.0}",
+			offers[0].cost, offers[0].capacity)
+	}
+}
+
+func TestDispatchableOffersWithBlocks(t *testing.T) {
+	disp := Dispatchable{
+		Cost:     5.0,
+		Capacity: 10.0,
+		Units:    1.0,
+		Blocks:   &BlockList{{Share: 0.5, Cost: 1.0}, {Share: 0.25, Cost: 3.0}},
+	}
+
+	tests := []struct {
+		cost, capacity float64
+	}{
+		{1.0, 5.0},
+		{3.0, 2.5},
+		{5.0, 2.5}, // Remainder offered at Cost.
+	}
+
+	offers := disp.offers()
+
+	if len(offers) != len(tests) {
+		t.Fatalf("Dispatchable.offers() returned %d offers, want %d",
+			len(offers), len(tests))
+	}
+
+	for i, test := range tests {
+		if offers[i].producer != &disp {
+			t.Errorf("Dispatchable.offers()[%d] has the wrong producer", i)
+		}
+
+		if offers[i].cost != test.cost || offers[i].capacity != test.capacity {
+			t.Errorf("Dispatchable.offers()[%d] = {Cost: %f, Capacity: %f}, "+
+				"want {Cost: %f, Capacity: %f}", i, offers[i].cost,
+				offers[i].capacity, test.cost, test.capacity)
+		}
+	}
+}
+
+// Asserts that blocks whose shares exceed the whole capacity are capped.
+func TestDispatchableOffersWithExcessBlocks(t *testing.T) {
+	disp := Dispatchable{
+		Cost:     5.0,
+		Capacity: 10.0,
+		Units:    1.0,
+		Blocks: &BlockList{
+			{Share: 0.75, Cost: 1.0},
+			{Share: 0.5, Cost: 3.0},
+			{Share: 0.5, Cost: 4.0},
+		},
+	}
+
+	tests := []struct {
+		cost, capacity float64
+	}{
+		{1.0, 7.5},
+		{3.0, 2.5},
+	}
+
+	offers := disp.offers()
+
+	if len(offers) != len(tests) {
+		t.Fatalf("Dispatchable.offers() returned %d offers, want %d",
+			len(offers), len(tests))
+	}
+
+	for i, test := range tests {
+		if offers[i].cost != test.cost || offers[i].capacity != test.capacity {
+			t.Errorf("Dispatchable.offers()[%d] = {Cost: %f, Capacity: %f}, "+
+				"want {Cost: %f, Capacity: %f}", i, offers[i].cost,
+				offers[i].capacity, test.cost, test.capacity)
+		}
+	}
+}
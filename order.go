@@ -1,58 +1,246 @@
 package merit
 
+import "math"
+
 // Order contains information about the participants in the merit order.
 type Order struct {
 	Consumers     []*Consumer
 	AlwaysOns     []*AlwaysOn
 	Dispatchables DispatchableList
-	Flexibles     []Flexlike
-	PriceSetters  []*Dispatchable
+	Flexibles     []Flexible
+	Imports       []*Import
+	Exports       []*Export
+	PriceSetters  []Participant
+	Prices        []float64
+
+	// Demands and Producers are participants of other types which use energy,
+	// and which produce a fixed amount of energy in each frame, respectively.
+	// Demands which implement Bidder are price-sensitive.
+	Demands   []Participant
+	Producers []Participant
+
+	// Suppliers are participants of other types which offer capacity like a
+	// Dispatchable.
+	Suppliers []Supplier
+
+	// Unmet is the demand which could not be met in each frame.
+	Unmet []float64
+
+	// Curtailed is the always-on production in each frame which was neither
+	// used to meet demand nor absorbed by flexibles.
+	Curtailed []float64
+
+	// ProRata causes dispatchables with equal costs to share load in
+	// proportion to their capacity. By default they are used one after
+	// another in the order in which they were added.
+	ProRata bool
+
+	// ScarcityPrice is the price of energy in frames where demand cannot be
+	// met. When zero, the price is the cost of the most expensive dispatchable
+	// or import used in the frame.
+	ScarcityPrice float64
+
+	// Stages are the steps used to calculate each frame. DefaultStages are used
+	// when empty.
+	Stages []Stage
+
+	// Observers are notified as the order is calculated by Calculate or
+	// CalculateParallel.
+	Observers []Observer
+
+	// offers contains the blocks of capacity offered by the dispatchables,
+	// sorted by cost. Set at the start of each calculation.
+	offers offerList
+
+	// charging and discharging contain the flexibles in the order in which
+	// they absorb and produce energy. Set at the start of each calculation.
+	charging    ChargeList
+	discharging DischargeList
+
+	// tracker remembers the inputs of the order when it was last calculated.
+	// Nil unless the order TrackChanges.
+	tracker *tracker
 }
 
 // NewOrder creates and returns new merit order. Prefer this over creating an
 // Order{} directly.
 func NewOrder() Order {
-	return Order{PriceSetters: make([]*Dispatchable, 8760)}
+	return Order{
+		PriceSetters: make([]Participant, 8760),
+		Prices:       make([]float64, 8760),
+		Unmet:        make([]float64, 8760),
+		Curtailed:    make([]float64, 8760),
+	}
+}
+
+// PriceAt returns the price of energy in frame, as set by the participant in
+// PriceSetters.
+func (o *Order) PriceAt(frame int) float64 {
+	return o.Prices[frame]
+}
+
+// UnmetAt returns the demand which could not be met in frame.
+func (o *Order) UnmetAt(frame int) float64 {
+	if o.Unmet == nil {
+		return 0
+	}
+
+	return o.Unmet[frame]
+}
+
+// CurtailedAt returns the always-on production which was curtailed in frame.
+func (o *Order) CurtailedAt(frame int) float64 {
+	if o.Curtailed == nil {
+		return 0
+	}
+
+	return o.Curtailed[frame]
+}
+
+// setUnmetAt records the demand which could not be met in frame, and the
+// production which was curtailed. Orders created without NewOrder record
+// neither.
+func (o *Order) setUnmetAt(frame int, unmet, curtailed float64) {
+	if o.Unmet != nil {
+		o.Unmet[frame] = math.Max(unmet, 0)
+	}
+
+	if o.Curtailed != nil {
+		o.Curtailed[frame] = math.Max(curtailed, 0)
+	}
+}
+
+// resetAt removes the load assigned to dispatchables, suppliers and imports,
+// demand shed by consumers and exports, the load of any flexibles which may be
+// reset, and the results of participants which implement Forgetter, in frame.
+// Dispatchables with more than one block accumulate load from each block, so
+// each calculation of a frame must start from nothing.
+func (o *Order) resetAt(frame int) {
+	for _, producer := range o.Dispatchables {
+		producer.resetAt(frame)
+	}
+
+	for _, imp := range o.Imports {
+		imp.resetAt(frame)
+	}
+
+	for _, consumer := range o.Consumers {
+		consumer.resetAt(frame)
+	}
+
+	for _, supplier := range o.Suppliers {
+		supplier.SetLoadAt(frame, 0)
+	}
+
+	for _, flex := range o.Flexibles {
+		switch f := flex.(type) {
+		case resetter:
+			f.resetAt(frame)
+		case Forgetter:
+			f.Forget(frame, frame+1)
+		}
+	}
+
+	forgetAll(o.Demands, frame, frame+1)
+	forgetAll(o.Suppliers, frame, frame+1)
+
+	for _, export := range o.Exports {
+		export.resetAt(frame)
+	}
 }
 
 // DemandAt returns the total demand for energy in frame.
 func (o *Order) DemandAt(frame int) float64 {
 	var sum float64
 
-	for _, consumer := range o.Consumers {
+	for _, consumer := range o.demands() {
 		sum += consumer.LoadAt(frame)
 	}
 
 	return sum
 }
 
+// demands returns the consumers and other demands in the order.
+func (o *Order) demands() []Participant {
+	list := make([]Participant, 0, len(o.Consumers)+len(o.Demands))
+
+	for _, consumer := range o.Consumers {
+		list = append(list, consumer)
+	}
+
+	return append(list, o.Demands...)
+}
+
+// producers returns the always-on and other producers in the order.
+func (o *Order) producers() []Participant {
+	list := make([]Participant, 0, len(o.AlwaysOns)+len(o.Producers))
+
+	for _, producer := range o.AlwaysOns {
+		list = append(list, producer)
+	}
+
+	return append(list, o.Producers...)
+}
+
 // AddConsumer adds a Consumer to the merit order.
 func (o *Order) AddConsumer(c *Consumer) {
 	o.Consumers = append(o.Consumers, c)
 }
 
+// AddDemand adds a participant of another type which uses energy to the merit
+// order. The demand is price-sensitive when the participant implements Bidder.
+func (o *Order) AddDemand(d Participant) {
+	o.Demands = append(o.Demands, d)
+}
+
 // AddAlwaysOn adds an AlwaysOn producer to the merit order.
 func (o *Order) AddAlwaysOn(a *AlwaysOn) {
 	o.AlwaysOns = append(o.AlwaysOns, a)
 }
 
+// AddProducer adds a participant of another type with a fixed amount of
+// production in each frame to the merit order.
+func (o *Order) AddProducer(p Participant) {
+	o.Producers = append(o.Producers, p)
+}
+
 // AddDispatchable adds a Dispatchable producer to the merit order.
 func (o *Order) AddDispatchable(d *Dispatchable) {
 	o.Dispatchables = append(o.Dispatchables, d)
 }
 
+// AddSupplier adds a participant of another type which offers capacity like a
+// Dispatchable to the merit order.
+func (o *Order) AddSupplier(s Supplier) {
+	o.Suppliers = append(o.Suppliers, s)
+}
+
 // AddFlex adds a Flex participant to the merit order.
 func (o *Order) AddFlex(f *Flex) {
-	// if fl, ok := f.(*Flexlike); ok {
-	// 	o.Flexibles = append(o.Flexibles, fl)
-	// }
+	o.AddFlexible(f)
+}
 
-	// o.Flexibles = append(o.Flexibles, (Flexlike)(*f))
-	// o.Flexibles = append(o.Flexibles, f.(*Flexlike))
+// AddStorage adds a Storage participant to the merit order.
+func (o *Order) AddStorage(s *Storage) {
+	o.AddFlexible(s)
+}
+
+// AddSink adds a Sink participant to the merit order.
+func (o *Order) AddSink(s *Sink) {
+	o.AddFlexible(s)
+}
+
+// AddFlexible adds any Flexible participant to the merit order.
+func (o *Order) AddFlexible(f Flexible) {
 	o.Flexibles = append(o.Flexibles, f)
 }
 
-// AddStorage adds a Flex participant to the merit order.
-func (o *Order) AddStorage(s *Storage) {
-	o.Flexibles = append(o.Flexibles, s)
+// AddImport adds an Import to the merit order.
+func (o *Order) AddImport(i *Import) {
+	o.Imports = append(o.Imports, i)
+}
+
+// AddExport adds an Export to the merit order.
+func (o *Order) AddExport(e *Export) {
+	o.Exports = append(o.Exports, e)
 }
@@ -0,0 +1,58 @@
+package merit
+
+// PriceSetter is implemented by flexibles which may set the price of energy
+// when they meet the last of the demand in a frame. PriceAt receives the
+// replacement cost: the cost of the dispatchable which would otherwise have
+// met that demand. It returns false when the flexible does not set the price,
+// in which case the cheapest dispatchable does.
+type PriceSetter interface {
+	PriceAt(frame int, replacement float64) (float64, bool)
+}
+
+// priceObserver is implemented by participants which need to know the price of
+// energy once a frame has been calculated.
+type priceObserver interface {
+	observePriceAt(frame int, price float64)
+}
+
+// setPriceAt records the participant which set the price in frame, and the
+// price it set.
+func (o *Order) setPriceAt(frame int, setter Participant, price float64) {
+	o.PriceSetters[frame] = setter
+	o.Prices[frame] = price
+
+	for _, flex := range o.Flexibles {
+		if observer, ok := flex.(priceObserver); ok {
+			observer.observePriceAt(frame, price)
+		}
+	}
+}
+
+// replacementCostAt returns the cost of the dispatchable which would be needed
+// to meet demand of amount in frame. When the dispatchables have insufficient
+// capacity, the cost of the most expensive is returned.
+func (o *Order) replacementCostAt(frame int, amount float64) float64 {
+	var cost float64
+
+	for _, offer := range o.offersAt(frame) {
+		cost = offer.cost
+		amount -= offer.capacity
+
+		if amount <= 0 {
+			break
+		}
+	}
+
+	return cost
+}
+
+// scarcityPrice returns the participant which sets the price of a frame in
+// which demand cannot be met, and the price. This is the ScarcityPrice of the
+// order when set, or otherwise the price of the most expensive offer used.
+func (o *Order) scarcityPrice(setter Participant, price float64) (Participant, float64) {
+	if o.ScarcityPrice > 0 {
+		return nil, o.ScarcityPrice
+	}
+
+	return setter, price
+}
@@ -0,0 +1,114 @@
+package merit
+
+import "testing"
+
+func TestOrderReplacementCostAt(t *testing.T) {
+	order := NewOrder()
+	order.AddDispatchable(&Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0})
+	order.AddDispatchable(&Dispatchable{Cost: 3.0, Capacity: 1.0, Units: 1.0})
+	prepare(&order)
+
+	tests := []struct {
+		amount, want float64
+	}{
+		{0.5, 1.0},
+		{1.0, 1.0},
+		{1.5, 3.0},
+		{5.0, 3.0}, // Insufficient capacity.
+	}
+
+	for _, test := range tests {
+		if cost := order.replacementCostAt(0, test.amount); cost != test.want {
+			t.Errorf("Order.replacementCostAt(0, %f) = %f, want %f",
+				test.amount, cost, test.want)
+		}
+	}
+}
+
+// Asserts that a storage meeting the last of the demand sets the price
+// according to its pricing.
+func TestCalculateStorageSetsPrice(t *testing.T) {
+	tests := []struct {
+		pricing StoragePricing
+		want    float64
+	}{
+		{DischargePricing, 2.5},
+		{ChargingCostPricing, 1.0},
+		{OpportunityCostPricing, 4.0},
+	}
+
+	for _, test := range tests {
+		st := Storage{
+			Flex: Flex{
+				Key:            "store",
+				Capacity:       2.0,
+				Units:          1.0,
+				DischargePrice: 2.5,
+			},
+			Pricing: test.pricing,
+			reserve: NewReserveWithoutDecay(10.0),
+		}
+
+		ao := AlwaysOn{Profile: [8760]float64{2.0, 0.0}, TotalProduction: 1.0}
+		cheap := Dispatchable{Cost: 1.0, Capacity: 0.5, Units: 1.0}
+		dear := Dispatchable{Cost: 4.0, Capacity: 5.0, Units: 1.0}
+		cons := Consumer{Profile: [8760]float64{1.0, 1.0}, TotalDemand: 1.0}
+
+		order := NewOrder()
+		order.AddConsumer(&cons)
+		order.AddAlwaysOn(&ao)
+		order.AddStorage(&st)
+		order.AddDispatchable(&cheap)
+		order.AddDispatchable(&dear)
+
+		Calculate(order)
+
+		if setter := order.PriceSetters[1]; setter != &st {
+			t.Errorf("Storage{Pricing: %d} was not the price setter in "+
+				"frame 1", test.pricing)
+		}
+
+		if price := order.PriceAt(1); price != test.want {
+			t.Errorf("Storage{Pricing: %d} set price %f in frame 1, want %f",
+				test.pricing, price, test.want)
+		}
+
+		if load := cheap.LoadAt(1); load != 0.0 {
+			t.Errorf("Storage{Pricing: %d} left cheap load %f in frame 1, "+
+				"want 0.0", test.pricing, load)
+		}
+	}
+}
+
+// Asserts that a storage without Pricing leaves the cheapest dispatchable to
+// set the price when it meets the last of the demand.
+func TestCalculateStorageWithoutPricing(t *testing.T) {
+	st := Storage{
+		Flex:    Flex{Capacity: 2.0, Units: 1.0},
+		reserve: NewReserveWithoutDecay(10.0),
+	}
+
+	ao := AlwaysOn{Profile: [8760]float64{2.0, 0.0}, TotalProduction: 1.0}
+	cheap := Dispatchable{Cost: 1.0, Capacity: 0.5, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{1.0, 1.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddAlwaysOn(&ao)
+	order.AddStorage(&st)
+	order.AddDispatchable(&cheap)
+
+	Calculate(order)
+
+	if load := st.LoadAt(1); load != 1.0 {
+		t.Errorf("Storage.LoadAt(1) = %f, want 1.0", load)
+	}
+
+	if setter := order.PriceSetters[1]; setter != &cheap {
+		t.Errorf("Storage without Pricing changed the price setter in frame 1")
+	}
+
+	if price := order.PriceAt(1); price != 1.0 {
+		t.Errorf("Storage without Pricing set price %f in frame 1, want 1.0", price)
+	}
+}
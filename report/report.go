@@ -0,0 +1,118 @@
+// Package report writes a self-contained HTML report of a calculated merit
+// order, with summary tables, charts and a section for each participant. The
+// report has no external dependencies, and may be opened in any browser.
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+
+	merit "github.com/antw/merit-go"
+	"github.com/antw/merit-go/chart"
+)
+
+// Options customises a report.
+type Options struct {
+	// Title is shown at the top of the report.
+	Title string
+
+	// Start and End are the frames shown in the load chart. The first week of
+	// the year is shown when both are zero.
+	Start, End int
+}
+
+// document contains everything shown in the report.
+type document struct {
+	Title        string
+	Totals       totals
+	Participants []participant
+	Charts       []figure
+}
+
+// figure is a chart embedded in the report.
+type figure struct {
+	Title string
+	SVG   template.HTML
+}
+
+// Write writes an HTML report of the order, which must already have been
+// calculated.
+func Write(w io.Writer, order merit.Order, opts Options) error {
+	if opts.Title == "" {
+		opts.Title = "Merit order"
+	}
+
+	if opts.Start == 0 && opts.End == 0 {
+		opts.End = 168
+	}
+
+	doc := document{Title: opts.Title, Totals: summariseOrder(order)}
+
+	for i, m := range members(order) {
+		doc.Participants = append(doc.Participants, summarise(order, m, i))
+	}
+
+	identify(doc.Participants)
+
+	charts, err := figures(order, opts, doc.Totals.PeakFrame)
+
+	if err != nil {
+		return err
+	}
+
+	doc.Charts = charts
+
+	return page.Execute(w, doc)
+}
+
+// figures renders the charts shown in the report.
+func figures(order merit.Order, opts Options, peak int) ([]figure, error) {
+	var storages []*merit.Storage
+
+	for _, flex := range order.Flexibles {
+		if storage, ok := flex.(*merit.Storage); ok {
+			storages = append(storages, storage)
+		}
+	}
+
+	renderers := []struct {
+		title  string
+		render func(w io.Writer) error
+	}{
+		{"Load", func(w io.Writer) error {
+			return chart.LoadStack(w, order, opts.Start, opts.End)
+		}},
+		{"Price duration", func(w io.Writer) error {
+			return chart.PriceDuration(w, order)
+		}},
+		{"Supply at peak demand", func(w io.Writer) error {
+			return chart.SupplyStack(w, order, peak)
+		}},
+	}
+
+	if len(storages) > 0 {
+		renderers = append(renderers, struct {
+			title  string
+			render func(w io.Writer) error
+		}{"Storage", func(w io.Writer) error {
+			return chart.StorageLevel(w, storages, opts.Start, opts.End)
+		}})
+	}
+
+	var list []figure
+
+	for _, r := range renderers {
+		var buf bytes.Buffer
+
+		if err := r.render(&buf); err != nil {
+			return nil, err
+		}
+
+		// The charts are written by this module, and escape any text they
+		// contain.
+		list = append(list, figure{Title: r.title, SVG: template.HTML(buf.String())})
+	}
+
+	return list, nil
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	merit "github.com/antw/merit-go"
+)
+
+func testOrder() merit.Order {
+	order := merit.NewOrder()
+
+	order.AddConsumer(&merit.Consumer{
+		Key:         "household",
+		Profile:     [8760]float64{1.0, 2.0, 8.0},
+		TotalDemand: 1.0,
+	})
+
+	order.AddAlwaysOn(&merit.AlwaysOn{
+		Key:             "solar",
+		Profile:         [8760]float64{0.5, 1.0},
+		TotalProduction: 1.0,
+	})
+
+	order.AddDispatchable(&merit.Dispatchable{
+		Key:            "gas <ccgt>",
+		Cost:           2.0,
+		Capacity:       5.0,
+		Units:          1.0,
+		EmissionFactor: 0.5,
+	})
+
+	merit.Calculate(order)
+
+	return order
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Write(&buf, testOrder(), Options{Title: "Scenario <A>"}); err != nil {
+		t.Fatalf("Write returned an error: %s", err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"<title>Scenario &lt;A&gt;</title>",
+		"<svg",
+		`id="household"`,
+		`href="#gas-ccgt"`,
+		"gas &lt;ccgt&gt;",
+		"Always-on",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write output does not contain %q", want)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	order := testOrder()
+	disp := order.Dispatchables[0]
+
+	row := summarise(order, member{Participant: disp}, 0)
+
+	// Demand of 8 in frame 2 is limited to the capacity of 5.
+	if row.Produced != 6.5 {
+		t.Errorf("summarise Produced = %f, want %f", row.Produced, 6.5)
+	}
+
+	if row.FullLoadHours != 1.3 {
+		t.Errorf("summarise FullLoadHours = %f, want %f", row.FullLoadHours, 1.3)
+	}
+
+	if row.Revenue != 13.0 {
+		t.Errorf("summarise Revenue = %f, want %f", row.Revenue, 13.0)
+	}
+
+	if row.Emissions != 3.25 {
+		t.Errorf("summarise Emissions = %f, want %f", row.Emissions, 3.25)
+	}
+}
+
+func TestSummariseOrder(t *testing.T) {
+	sum := summariseOrder(testOrder())
+
+	if sum.Unmet != 3.0 || sum.UnmetFrames != 1 {
+		t.Errorf("summariseOrder unmet = %f in %d frames, want 3 in 1",
+			sum.Unmet, sum.UnmetFrames)
+	}
+
+	if sum.PeakFrame != 2 {
+		t.Errorf("summariseOrder PeakFrame = %d, want 2", sum.PeakFrame)
+	}
+}
+
+// Asserts that the report agrees with the order about unmet demand in a region
+// whose demand is met through an interconnector.
+func TestSummariseOrderInNetwork(t *testing.T) {
+	regionA := &merit.Region{Key: "a", Order: merit.NewOrder()}
+	regionA.Order.AddDispatchable(&merit.Dispatchable{Cost: 1.0, Capacity: 5.0, Units: 1.0})
+
+	regionB := &merit.Region{Key: "b", Order: merit.NewOrder()}
+	regionB.Order.AddConsumer(&merit.Consumer{Profile: [8760]float64{2.0}, TotalDemand: 1.0})
+
+	err := merit.CalculateNetwork(merit.Network{
+		Regions: []*merit.Region{regionA, regionB},
+		Interconnectors: []*merit.Interconnector{
+			{From: regionA, To: regionB, Capacity: [8760]float64{5.0}},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("CalculateNetwork returned an error: %s", err)
+	}
+
+	if sum := summariseOrder(regionB.Order); sum.Unmet != 0.0 || sum.UnmetFrames != 0 {
+		t.Errorf("summariseOrder unmet = %f in %d frames, want none",
+			sum.Unmet, sum.UnmetFrames)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	participants := []participant{
+		{Key: "Gas <CCGT>"},
+		{Key: "participant 2"},
+		{Key: "gas ccgt"},
+		{Key: "gas-ccgt"},
+		{Key: "???"},
+	}
+
+	identify(participants)
+
+	want := []string{"gas-ccgt", "participant-2", "gas-ccgt-2", "gas-ccgt-3", "participant"}
+
+	for i, p := range participants {
+		if p.ID != want[i] {
+			t.Errorf("identify gave %q the ID %q, want %q", p.Key, p.ID, want[i])
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	merit "github.com/antw/merit-go"
+)
+
+// participant is an entry in a report, describing the results of one
+// participant in the merit order.
+type participant struct {
+	Key  string
+	Kind string
+
+	// ID identifies the section of the participant in the report. IDs are
+	// derived from the key, and are unique within a report.
+	ID string
+
+	// Produced and Consumed are the total energy produced and consumed by the
+	// participant over the year.
+	Produced float64
+	Consumed float64
+
+	Peak          float64
+	Capacity      float64
+	FullLoadHours float64
+	Running       int
+
+	// Revenue is the price of energy multiplied by the load in each frame.
+	// Energy consumed is a cost, and reduces revenue.
+	Revenue   float64
+	Emissions float64
+}
+
+// emitter is implemented by participants whose production emits CO2.
+type emitter interface {
+	TotalEmissions() float64
+}
+
+// member is a participant in the merit order. The load of consumers and
+// exports is energy consumed rather than produced.
+type member struct {
+	merit.Participant
+	demand bool
+}
+
+// summarise computes the results of a participant over the year.
+func summarise(order merit.Order, m member, i int) participant {
+	key, kind := describe(m.Participant, i)
+	stats := merit.ParticipantStats(m.Participant)
+
+	row := participant{
+		Key:           key,
+		Kind:          kind,
+		Produced:      stats.Energy,
+		Consumed:      stats.Absorbed,
+		Peak:          math.Max(stats.Peak, -stats.Min),
+		Capacity:      stats.Capacity,
+		FullLoadHours: stats.FullLoadHours,
+		Running:       stats.RunningFrames,
+	}
+
+	if m.demand {
+		row.Produced, row.Consumed = row.Consumed, row.Produced
+	}
+
+	for frame := 0; frame < 8760; frame++ {
+		load := m.LoadAt(frame)
+
+		if m.demand {
+			load = -load
+		}
+
+		row.Revenue += order.PriceAt(frame) * load
+	}
+
+	if e, ok := m.Participant.(emitter); ok {
+		row.Emissions = e.TotalEmissions()
+	}
+
+	return row
+}
+
+// describe returns the key of the participant, and the kind of participant it
+// is. Participants without a key are named by their position in the report.
+func describe(p merit.Participant, i int) (key, kind string) {
+	switch p.(type) {
+	case *merit.AlwaysOn:
+		kind = "Always-on"
+	case *merit.Consumer:
+		kind = "Consumer"
+	case *merit.Dispatchable:
+		kind = "Dispatchable"
+	case *merit.Import:
+		kind = "Import"
+	case *merit.Storage:
+		kind = "Storage"
+	case *merit.Sink:
+		kind = "Sink"
+	case *merit.Flex:
+		kind = "Flex"
+	case *merit.Export:
+		kind = "Export"
+	default:
+		kind = fmt.Sprintf("%T", p)
+	}
+
+	if key = merit.KeyOf(p); key == "" {
+		key = fmt.Sprintf("participant %d", i+1)
+	}
+
+	return key, kind
+}
+
+// members returns every participant in the order, in the order in which they
+// appear in the report.
+func members(order merit.Order) []member {
+	var list []member
+
+	for _, consumer := range order.Consumers {
+		list = append(list, member{consumer, true})
+	}
+
+	for _, demand := range order.Demands {
+		list = append(list, member{demand, true})
+	}
+
+	for _, producer := range order.AlwaysOns {
+		list = append(list, member{producer, false})
+	}
+
+	for _, producer := range order.Producers {
+		list = append(list, member{producer, false})
+	}
+
+	for _, flex := range order.Flexibles {
+		list = append(list, member{flex, false})
+	}
+
+	for _, producer := range order.Dispatchables {
+		list = append(list, member{producer, false})
+	}
+
+	for _, supplier := range order.Suppliers {
+		list = append(list, member{supplier, false})
+	}
+
+	for _, imp := range order.Imports {
+		list = append(list, member{imp, false})
+	}
+
+	for _, export := range order.Exports {
+		list = append(list, member{export, true})
+	}
+
+	return list
+}
+
+// totals summarises the whole merit order over the year.
+type totals struct {
+	Demand      float64
+	Unmet       float64
+	UnmetFrames int
+	Emissions   float64
+
+	// AveragePrice is the demand-weighted average price of energy.
+	AveragePrice float64
+	PeakFrame    int
+}
+
+func summariseOrder(order merit.Order) totals {
+	var sum totals
+	var cost, peak float64
+
+	for frame := 0; frame < 8760; frame++ {
+		demand := order.DemandAt(frame)
+
+		sum.Demand += demand
+		cost += demand * order.PriceAt(frame)
+
+		if unmet := order.UnmetAt(frame); unmet > 0 {
+			sum.Unmet += unmet
+			sum.UnmetFrames++
+		}
+
+		if demand > peak {
+			peak = demand
+			sum.PeakFrame = frame
+		}
+	}
+
+	if sum.Demand > 0 {
+		sum.AveragePrice = cost / sum.Demand
+	}
+
+	sum.Emissions = order.TotalEmissions()
+
+	return sum
+}
+
+// identify gives each participant an ID made of the lower-case letters and
+// digits of its key, with other characters replaced by hyphens. Participants
+// whose IDs would otherwise be the same are numbered.
+func identify(participants []participant) {
+	used := make(map[string]bool, len(participants))
+
+	for i := range participants {
+		base := slug(participants[i].Key)
+		id := base
+
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		used[id] = true
+		participants[i].ID = id
+	}
+}
+
+// slug returns the key with every run of characters other than lower-case
+// letters and digits replaced by a hyphen.
+func slug(key string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(key) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+
+	if id := strings.TrimSuffix(b.String(), "-"); id != "" {
+		return id
+	}
+
+	return "participant"
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+)
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"number": func(value float64) string {
+		return fmt.Sprintf("%.2f", value)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.3em 0.8em; }
+td.number { text-align: right; font-variant-numeric: tabular-nums; }
+th { text-align: left; }
+figure { margin: 1em 0; }
+section.participant { border-top: 2px solid #eee; margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Demand</th><td class="number">{{number .Totals.Demand}}</td></tr>
+<tr><th>Unmet demand</th><td class="number">{{number .Totals.Unmet}}</td></tr>
+<tr><th>Frames with unmet demand</th><td class="number">{{.Totals.UnmetFrames}}</td></tr>
+<tr><th>Average price</th><td class="number">{{number .Totals.AveragePrice}}</td></tr>
+<tr><th>Emissions</th><td class="number">{{number .Totals.Emissions}}</td></tr>
+</table>
+
+<h2>Participants</h2>
+<table>
+<tr>
+<th>Participant</th><th>Type</th><th>Produced</th><th>Consumed</th>
+<th>Full-load hours</th><th>Revenue</th><th>Emissions</th>
+</tr>
+{{range .Participants}}<tr>
+<td><a href="#{{.ID}}">{{.Key}}</a></td><td>{{.Kind}}</td>
+<td class="number">{{number .Produced}}</td>
+<td class="number">{{number .Consumed}}</td>
+<td class="number">{{number .FullLoadHours}}</td>
+<td class="number">{{number .Revenue}}</td>
+<td class="number">{{number .Emissions}}</td>
+</tr>
+{{end}}</table>
+
+<h2>Charts</h2>
+{{range .Charts}}<figure>
+{{.SVG}}
+<figcaption>{{.Title}}</figcaption>
+</figure>
+{{end}}
+{{range .Participants}}<section class="participant" id="{{.ID}}">
+<h3>{{.Key}}</h3>
+<table>
+<tr><th>Type</th><td>{{.Kind}}</td></tr>
+<tr><th>Capacity</th><td class="number">{{number .Capacity}}</td></tr>
+<tr><th>Peak load</th><td class="number">{{number .Peak}}</td></tr>
+<tr><th>Energy produced</th><td class="number">{{number .Produced}}</td></tr>
+<tr><th>Energy consumed</th><td class="number">{{number .Consumed}}</td></tr>
+<tr><th>Full-load hours</th><td class="number">{{number .FullLoadHours}}</td></tr>
+<tr><th>Frames running</th><td class="number">{{.Running}}</td></tr>
+<tr><th>Revenue</th><td class="number">{{number .Revenue}}</td></tr>
+<tr><th>Emissions</th><td class="number">{{number .Emissions}}</td></tr>
+</table>
+</section>
+{{end}}</body>
+</html>
+`))
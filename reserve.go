@@ -49,12 +49,46 @@ func (r *reserve) At(frame int) float64 {
 	return r.store[frame]
 }
 
+// peek returns how much energy is stored in the reserve at the end of the given
+// frame, as At, but without recording an amount which has not yet been
+// computed.
+func (r *reserve) peek(frame int) float64 {
+	if r.store[frame] != -1 {
+		return r.store[frame]
+	}
+
+	previous := r.store[frame-1]
+
+	if previous == -1 {
+		return 0.0
+	}
+
+	if r.decay == nil {
+		return previous
+	}
+
+	return previous - math.Min(previous, r.decay(frame, previous))
+}
+
 // Set sets the amount in the reserve for the chosen frame. Ignores volume
 // constraints and assumes you will check this yourself.
 func (r *reserve) Set(frame int, amount float64) {
 	r.store[frame] = amount
 }
 
+// forget discards the amounts stored in frames start to end (exclusive). They
+// are computed again, from the amount stored in the frame before, when next
+// needed.
+func (r *reserve) forget(start, end int) {
+	for frame := start; frame < end; frame++ {
+		if frame == 0 {
+			r.store[frame] = 0
+		} else {
+			r.store[frame] = -1
+		}
+	}
+}
+
 // Add adds the given amount of energy in the chosen frame, ensuring that the
 // amount stored does not exceed the volume of the reserve.
 //
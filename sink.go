@@ -0,0 +1,64 @@
+package merit
+
+// Sink is a flexible which absorbs energy and converts it to another carrier,
+// such as power-to-heat, power-to-gas, or an electrolyser. A sink whose
+// Efficiency is zero represents curtailment.
+//
+// Sinks absorb excess energy in order of their ChargePrice. They also buy
+// energy from any dispatchable which is cheaper than their ChargePrice, after
+// the demand of consumers has been met.
+type Sink struct {
+	Flex
+
+	// Efficiency is the amount of output energy produced for each unit of
+	// energy absorbed.
+	Efficiency float64
+
+	// Carrier is the type of energy produced by the sink, such as "heat" or
+	// "hydrogen".
+	Carrier string
+}
+
+// OutputAt returns the amount of energy converted to the output carrier in
+// frame.
+func (s *Sink) OutputAt(frame int) float64 {
+	return -s.load[frame] * s.Efficiency
+}
+
+// TotalOutput returns the amount of energy converted to the output carrier over
+// the whole year.
+func (s *Sink) TotalOutput() float64 {
+	var sum float64
+
+	for frame := range s.load {
+		sum += s.OutputAt(frame)
+	}
+
+	return sum
+}
+
+// resetAt removes the energy absorbed by the sink in frame.
+func (s *Sink) resetAt(frame int) {
+	s.load[frame] = 0
+}
+
+// placeBidAt bids for as much energy as the sink can still absorb in frame, at
+// its ChargePrice. The energy is assumed to be absorbed; any which is later
+// shed is given back with addShedAt.
+func (s *Sink) placeBidAt(frame int) bid {
+	amount := s.TotalCapacity() + s.load[frame]
+
+	if amount < 0 {
+		amount = 0
+	}
+
+	s.load[frame] -= amount
+
+	return bid{owner: s, price: s.ChargePrice, amount: amount}
+}
+
+// addShedAt records that energy bid for by the sink in frame was too expensive
+// to absorb.
+func (s *Sink) addShedAt(frame int, amount float64) {
+	s.load[frame] += amount
+}
@@ -0,0 +1,180 @@
+package merit
+
+import "testing"
+
+func TestSinkOutput(t *testing.T) {
+	sink := Sink{
+		Flex:       Flex{Capacity: 2.0, Units: 1.0},
+		Efficiency: 0.5,
+		Carrier:    "heat",
+	}
+
+	sink.AssignExcessAt(0, 1.0)
+	sink.AssignExcessAt(1, 3.0)
+
+	tests := []struct {
+		frame int
+		want  float64
+	}{
+		{0, 0.5},
+		{1, 1.0}, // Limited by capacity.
+		{2, 0.0},
+	}
+
+	for _, test := range tests {
+		if output := sink.OutputAt(test.frame); output != test.want {
+			t.Errorf("Sink.OutputAt(%d) = %f, want %f",
+				test.frame, output, test.want)
+		}
+	}
+
+	if total := sink.TotalOutput(); total != 1.5 {
+		t.Errorf("Sink.TotalOutput() = %f, want 1.5", total)
+	}
+}
+
+func TestCalculateSinks(t *testing.T) {
+	p2h := Sink{
+		Flex:       Flex{Key: "p2h", Capacity: 2.0, Units: 1.0, ChargePrice: 3.0},
+		Efficiency: 0.8,
+		Carrier:    "heat",
+	}
+
+	curtailment := Sink{
+		Flex: Flex{Key: "curtailment", Capacity: 10.0, Units: 1.0, ChargePrice: 0.5},
+	}
+
+	ao := AlwaysOn{Profile: [8760]float64{2.0, 0.0, 13.0}, TotalProduction: 1.0}
+	disp := Dispatchable{Cost: 1.0, Capacity: 5.0, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{1.0, 1.0, 1.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddAlwaysOn(&ao)
+	order.AddSink(&curtailment)
+	order.AddSink(&p2h)
+	order.AddDispatchable(&disp)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame           int
+		wantP2H         float64
+		wantCurtailment float64
+		wantDisp        float64
+	}{
+		{0, -2.0, 0.0, 1.0},   // Excess, then buys from the dispatchable.
+		{1, -2.0, 0.0, 3.0},   // Buys from the dispatchable.
+		{2, -2.0, -10.0, 0.0}, // Excess is curtailed once p2h is full.
+	}
+
+	for _, test := range tests {
+		if load := p2h.LoadAt(test.frame); load != test.wantP2H {
+			t.Errorf("Calculate assigned p2h load %d = %f, want %f",
+				test.frame, load, test.wantP2H)
+		}
+
+		if load := curtailment.LoadAt(test.frame); load != test.wantCurtailment {
+			t.Errorf("Calculate assigned curtailment load %d = %f, want %f",
+				test.frame, load, test.wantCurtailment)
+		}
+
+		if load := disp.LoadAt(test.frame); load != test.wantDisp {
+			t.Errorf("Calculate assigned dispatchable load %d = %f, want %f",
+				test.frame, load, test.wantDisp)
+		}
+	}
+
+	if output := p2h.OutputAt(1); output != 1.6 {
+		t.Errorf("Sink.OutputAt(1) = %f, want 1.6", output)
+	}
+}
+
+// Asserts that a sink which buys nothing does not set the price when there are
+// no dispatchables.
+func TestCalculateSinkWithoutDispatchables(t *testing.T) {
+	sink := Sink{Flex: Flex{Capacity: 2.0, Units: 1.0, ChargePrice: 3.0}}
+	ao := AlwaysOn{Profile: [8760]float64{2.0}, TotalProduction: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{2.0}, TotalDemand: 1.0})
+	order.AddAlwaysOn(&ao)
+	order.AddSink(&sink)
+
+	Calculate(order)
+
+	if load := sink.LoadAt(0); load != 0.0 {
+		t.Errorf("Sink.LoadAt(0) = %f, want 0.0", load)
+	}
+
+	if setter, price := order.PriceSetters[0], order.PriceAt(0); setter != nil || price != 0.0 {
+		t.Errorf("Sink without dispatchables set price %f in frame 0, want 0.0", price)
+	}
+}
+
+// Asserts that sinks buy energy from dispatchables cheaper than their
+// ChargePrice in a network.
+func TestCalculateNetworkSinks(t *testing.T) {
+	sink := Sink{Flex: Flex{Key: "p2h", Capacity: 2.0, Units: 1.0, ChargePrice: 3.0}}
+	cheap := Dispatchable{Cost: 1.0, Capacity: 2.0, Units: 1.0}
+	dear := Dispatchable{Cost: 5.0, Capacity: 5.0, Units: 1.0}
+
+	regionA := &Region{Key: "a", Order: NewOrder()}
+	regionA.Order.AddConsumer(&Consumer{Profile: [8760]float64{1.0, 2.0}, TotalDemand: 1.0})
+	regionA.Order.AddSink(&sink)
+	regionA.Order.AddDispatchable(&cheap)
+
+	regionB := &Region{Key: "b", Order: NewOrder()}
+	regionB.Order.AddDispatchable(&dear)
+
+	CalculateNetwork(Network{Regions: []*Region{regionA, regionB}})
+
+	tests := []struct {
+		frame      int
+		wantSink   float64
+		wantPrice  float64
+		wantSetter Participant
+	}{
+		{0, -1.0, 3.0, &sink}, // Sink is the marginal buyer.
+		{1, 0.0, 1.0, &cheap}, // Nothing left for the sink.
+	}
+
+	for _, test := range tests {
+		if load := sink.LoadAt(test.frame); load != test.wantSink {
+			t.Errorf("Sink.LoadAt(%d) = %f, want %f", test.frame, load, test.wantSink)
+		}
+
+		if price := regionA.Order.PriceAt(test.frame); price != test.wantPrice {
+			t.Errorf("Price in frame %d = %f, want %f", test.frame, price, test.wantPrice)
+		}
+
+		if setter := regionA.Order.PriceSetters[test.frame]; setter != test.wantSetter {
+			t.Errorf("Wrong price setter in frame %d", test.frame)
+		}
+	}
+}
+
+// Asserts that sinks bid for energy when the order is cleared.
+func TestClearSinks(t *testing.T) {
+	sink := Sink{Flex: Flex{Capacity: 2.0, Units: 1.0, ChargePrice: 3.0}}
+	cheap := Dispatchable{Cost: 1.0, Capacity: 2.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0})
+	order.AddSink(&sink)
+	order.AddDispatchable(&cheap)
+
+	clearing := Clear(order)[0]
+
+	if load := sink.LoadAt(0); load != -1.0 {
+		t.Errorf("Sink.LoadAt(0) = %f, want -1.0", load)
+	}
+
+	if clearing.Price != 3.0 || clearing.PriceSetter != &sink {
+		t.Errorf("Clear()[0].Price = %f, want 3.0 set by the sink", clearing.Price)
+	}
+
+	if load := cheap.LoadAt(0); load != 2.0 {
+		t.Errorf("Dispatchable.LoadAt(0) = %f, want 2.0", load)
+	}
+}
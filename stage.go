@@ -0,0 +1,211 @@
+package merit
+
+// FrameState holds the progress of a frame as it passes through each Stage of
+// the calculation. Remaining is the demand not yet met, and Excess is energy
+// produced in excess of demand which has not yet been used.
+//
+// PriceSetter and Price are the participant which set the price of energy in
+// the frame, and the price it set. They are assigned to the Order once every
+// stage has run.
+type FrameState struct {
+	Frame     int
+	Remaining float64
+	Excess    float64
+
+	PriceSetter Participant
+	Price       float64
+
+	// observers are notified of each step of the frame. err is the first
+	// error returned by an observer, after which they are no longer notified.
+	observers []Observer
+	err       error
+}
+
+// observed returns whether observers are notified of the steps of the frame.
+func (s *FrameState) observed() bool {
+	return len(s.observers) > 0 && s.err == nil
+}
+
+// note notifies the observers of a step of the frame.
+func (s *FrameState) note(step Step) {
+	if !s.observed() {
+		return
+	}
+
+	if step.Participant != nil {
+		step.Key = nameOf(step.Participant)
+	}
+
+	for _, observer := range s.observers {
+		if err := observer.ObserveStep(s.Frame, step); err != nil {
+			s.err = err
+			return
+		}
+	}
+}
+
+// setPrice records the participant which set the price, and the price.
+func (s *FrameState) setPrice(setter Participant, price float64) {
+	s.PriceSetter = setter
+	s.Price = price
+}
+
+// Stage is a step in the calculation of a frame. The stages of an Order are run
+// in turn, each receiving the state left by the one before.
+type Stage interface {
+	Run(order Order, state *FrameState)
+}
+
+// DefaultStages returns the stages used to calculate an Order whose Stages are
+// empty: always-on production meets demand, excess is assigned to flexibles,
+// flexibles discharge to meet demand, and finally dispatchables are used.
+func DefaultStages() []Stage {
+	return []Stage{
+		AlwaysOnStage{},
+		ExcessStage{},
+		FlexibleStage{},
+		DispatchableStage{},
+	}
+}
+
+// AlwaysOnStage uses the production of AlwaysOn producers to meet demand. Any
+// production beyond demand is added to the excess.
+type AlwaysOnStage struct{}
+
+// Run implements Stage.
+func (AlwaysOnStage) Run(order Order, state *FrameState) {
+	for _, producer := range order.producers() {
+		produced := producer.LoadAt(state.Frame)
+
+		if produced > state.Remaining {
+			state.Excess += produced - state.Remaining
+			state.Remaining = 0
+		} else {
+			// The producer is providing no more energy than remaining demand.
+			// Take it all and continue with the next producer.
+			state.Remaining -= produced
+		}
+
+		state.note(Step{
+			Kind:        StepAlwaysOn,
+			Participant: producer,
+			Amount:      produced,
+			Remaining:   state.Remaining,
+		})
+	}
+}
+
+// ExcessStage offers excess energy to the flexibles, starting with those
+// willing to pay the most, until it has all been used.
+type ExcessStage struct{}
+
+// Run implements Stage.
+func (ExcessStage) Run(order Order, state *FrameState) {
+	for _, flex := range order.chargeList() {
+		// If there is no energy remaining to be assigned, we can exit early
+		// and - as an added bonus - prevent assigning tiny negatives resulting
+		// from floating point errors. This would otherwise mess up
+		// technologies which have a Reserve whose volume is 0.0.
+		if state.Excess <= 0.0 {
+			break
+		}
+
+		offered := state.Excess
+		state.Excess -= flex.AssignExcessAt(state.Frame, offered)
+
+		state.note(Step{
+			Kind:        StepExcess,
+			Participant: flex,
+			Amount:      offered - state.Excess,
+			Offered:     offered,
+			Remaining:   state.Remaining,
+		})
+	}
+}
+
+// FlexibleStage discharges flexibles, starting with the cheapest, to meet
+// remaining demand. A flexible which meets the last of the demand sets the
+// price when it implements PriceSetter and accepts; otherwise the cheapest
+// dispatchable does.
+type FlexibleStage struct{}
+
+// Run implements Stage.
+func (FlexibleStage) Run(order Order, state *FrameState) {
+	frame := state.Frame
+
+	if state.Remaining <= 0 {
+		// Always-on supply met all demand.
+		return
+	}
+
+	for _, producer := range order.dischargeList() {
+		maxLoad := producer.AvailableAt(frame)
+
+		if maxLoad < state.Remaining {
+			producer.SetLoadAt(frame, maxLoad)
+			state.Remaining -= maxLoad
+
+			state.note(Step{
+				Kind:        StepDischarge,
+				Participant: producer,
+				Amount:      maxLoad,
+				Remaining:   state.Remaining,
+				Reason:      "at capacity",
+			})
+
+			continue
+		}
+
+		// The price is determined before discharging, while the flexible
+		// still knows what it has stored.
+		if price, ok := flexiblePriceAt(order, producer, state); ok {
+			state.setPrice(producer, price)
+		} else if offers := order.offersAt(frame); len(offers) > 0 {
+			state.setPrice(offers[0].producer, offers[0].cost)
+		}
+
+		producer.SetLoadAt(frame, state.Remaining)
+
+		amount := state.Remaining
+		state.Remaining = 0
+
+		state.note(Step{
+			Kind:        StepDischarge,
+			Participant: producer,
+			Amount:      amount,
+			Reason:      "demand met",
+		})
+
+		return // All demand is assigned.
+	}
+}
+
+// DispatchableStage assigns load to dispatchables and imports in order of cost
+// until demand is met, shedding price-sensitive demand as the price rises.
+type DispatchableStage struct{}
+
+// Run implements Stage.
+func (DispatchableStage) Run(order Order, state *FrameState) {
+	assignDispatchables(order, state)
+}
+
+// stages returns the stages used to calculate the order.
+func (o *Order) stages() []Stage {
+	if len(o.Stages) == 0 {
+		return DefaultStages()
+	}
+
+	return o.Stages
+}
+
+// flexiblePriceAt returns the price set by a flexible which meets the remaining
+// demand of the state, and whether it sets the price at all.
+func flexiblePriceAt(order Order, producer Flexible, state *FrameState) (float64, bool) {
+	setter, ok := producer.(PriceSetter)
+
+	if !ok {
+		return 0, false
+	}
+
+	return setter.PriceAt(state.Frame, order.replacementCostAt(state.Frame, state.Remaining))
+}
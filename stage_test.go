@@ -0,0 +1,88 @@
+package merit
+
+import "testing"
+
+// reduceDemandStage is a Stage which meets a fixed amount of demand in every
+// frame, recording the demand remaining when it was run.
+type reduceDemandStage struct {
+	amount float64
+	seen   []float64
+}
+
+func (s *reduceDemandStage) Run(order Order, state *FrameState) {
+	s.seen = append(s.seen, state.Remaining)
+	state.Remaining -= s.amount
+}
+
+func TestDefaultStages(t *testing.T) {
+	stages := DefaultStages()
+
+	if len(stages) != 4 {
+		t.Fatalf("DefaultStages() returned %d stages, want 4", len(stages))
+	}
+
+	if _, ok := stages[len(stages)-1].(DispatchableStage); !ok {
+		t.Errorf("DefaultStages() should end with the DispatchableStage")
+	}
+}
+
+func TestCalculateCustomStage(t *testing.T) {
+	ao := AlwaysOn{Profile: [8760]float64{0.5, 0.5}, TotalProduction: 1.0}
+	disp := Dispatchable{Capacity: 5.0, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{2.0, 3.0}, TotalDemand: 1.0}
+
+	custom := &reduceDemandStage{amount: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddAlwaysOn(&ao)
+	order.AddDispatchable(&disp)
+
+	order.Stages = []Stage{
+		AlwaysOnStage{},
+		custom,
+		DispatchableStage{},
+	}
+
+	Calculate(order)
+
+	tests := []struct {
+		frame    int
+		wantSeen float64
+		wantLoad float64
+	}{
+		{0, 1.5, 0.5},
+		{1, 2.5, 1.5},
+	}
+
+	for _, test := range tests {
+		if seen := custom.seen[test.frame]; seen != test.wantSeen {
+			t.Errorf("Custom stage saw remaining demand %f in frame %d, "+
+				"want %f", seen, test.frame, test.wantSeen)
+		}
+
+		if load := disp.LoadAt(test.frame); load != test.wantLoad {
+			t.Errorf("Calculate assigned dispatchable load %d = %f, want %f",
+				test.frame, load, test.wantLoad)
+		}
+	}
+}
+
+func TestAlwaysOnStageExcess(t *testing.T) {
+	ao := AlwaysOn{Profile: [8760]float64{3.0}, TotalProduction: 1.0}
+
+	order := NewOrder()
+	order.AddAlwaysOn(&ao)
+
+	state := FrameState{Frame: 0, Remaining: 1.0}
+	AlwaysOnStage{}.Run(order, &state)
+
+	if state.Remaining != 0.0 {
+		t.Errorf("AlwaysOnStage left remaining demand %f, want 0.0",
+			state.Remaining)
+	}
+
+	if state.Excess != 2.0 {
+		t.Errorf("AlwaysOnStage left excess %f, want 2.0", state.Excess)
+	}
+}
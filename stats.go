@@ -0,0 +1,97 @@
+package merit
+
+import "math"
+
+// Stats summarises the load of a participant over the year.
+//
+// Energy is the total of the positive loads, and Absorbed the total of the
+// negative loads, such as energy stored by a flexible. Full-load hours are the
+// hours the participant would need to run at full capacity to produce and
+// absorb the same energy.
+type Stats struct {
+	Energy   float64
+	Absorbed float64
+
+	Peak      float64
+	PeakFrame int
+	Min       float64
+	MinFrame  int
+
+	Capacity       float64
+	FullLoadHours  float64
+	CapacityFactor float64
+
+	// RunningFrames is the number of frames in which the load is not zero.
+	RunningFrames int
+}
+
+// ParticipantStats computes the Stats of any participant. The capacity of
+// participants without a TotalCapacity, such as consumers and always-on
+// producers, is taken to be their peak load.
+func ParticipantStats(p Participant) Stats {
+	var stats Stats
+
+	stats.Peak = math.Inf(-1)
+	stats.Min = math.Inf(1)
+
+	for frame := 0; frame < 8760; frame++ {
+		load := p.LoadAt(frame)
+
+		if load > 0 {
+			stats.Energy += load
+		} else {
+			stats.Absorbed -= load
+		}
+
+		if load != 0 {
+			stats.RunningFrames++
+		}
+
+		if load > stats.Peak {
+			stats.Peak, stats.PeakFrame = load, frame
+		}
+
+		if load < stats.Min {
+			stats.Min, stats.MinFrame = load, frame
+		}
+	}
+
+	if c, ok := p.(interface{ TotalCapacity() float64 }); ok {
+		stats.Capacity = c.TotalCapacity()
+	} else {
+		stats.Capacity = math.Max(stats.Peak, -stats.Min)
+	}
+
+	if stats.Capacity > 0 {
+		stats.FullLoadHours = (stats.Energy + stats.Absorbed) / stats.Capacity
+		stats.CapacityFactor = stats.FullLoadHours / 8760
+	}
+
+	return stats
+}
+
+// Stats returns statistics describing the load of the dispatchable.
+func (d *Dispatchable) Stats() Stats {
+	return ParticipantStats(d)
+}
+
+// Stats returns statistics describing the production of the always-on
+// producer.
+func (a *AlwaysOn) Stats() Stats {
+	return ParticipantStats(a)
+}
+
+// Stats returns statistics describing the demand of the consumer.
+func (c *Consumer) Stats() Stats {
+	return ParticipantStats(c)
+}
+
+// Stats returns statistics describing the load of the flex.
+func (f *Flex) Stats() Stats {
+	return ParticipantStats(f)
+}
+
+// Stats returns statistics describing the load of the storage.
+func (s *Storage) Stats() Stats {
+	return ParticipantStats(s)
+}
@@ -0,0 +1,81 @@
+package merit
+
+import "testing"
+
+func TestDispatchableStats(t *testing.T) {
+	disp := Dispatchable{Capacity: 1.0, Units: 2.0}
+	cons := Consumer{Profile: [8760]float64{0.5, 3.0, 1.0}, TotalDemand: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+
+	Calculate(order)
+
+	stats := disp.Stats()
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"Energy", stats.Energy, 3.5},
+		{"Absorbed", stats.Absorbed, 0.0},
+		{"Peak", stats.Peak, 2.0},
+		{"PeakFrame", float64(stats.PeakFrame), 1},
+		{"Min", stats.Min, 0.0},
+		{"MinFrame", float64(stats.MinFrame), 3},
+		{"Capacity", stats.Capacity, 2.0},
+		{"FullLoadHours", stats.FullLoadHours, 1.75},
+		{"CapacityFactor", stats.CapacityFactor, 1.75 / 8760},
+		{"RunningFrames", float64(stats.RunningFrames), 3},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("Dispatchable.Stats() %s = %f, want %f",
+				test.name, test.got, test.want)
+		}
+	}
+}
+
+// Asserts that energy stored by a flexible is counted as absorbed.
+func TestFlexStats(t *testing.T) {
+	flex := Flex{Capacity: 1.0, Units: 1.0}
+
+	flex.SetLoadAt(0, 0.5)
+	flex.SetLoadAt(1, -1.0)
+
+	stats := flex.Stats()
+
+	if stats.Energy != 0.5 || stats.Absorbed != 1.0 {
+		t.Errorf("Flex.Stats() Energy, Absorbed = %f, %f, want 0.5, 1.0",
+			stats.Energy, stats.Absorbed)
+	}
+
+	if stats.Min != -1.0 || stats.MinFrame != 1 {
+		t.Errorf("Flex.Stats() Min = %f at %d, want -1.0 at 1",
+			stats.Min, stats.MinFrame)
+	}
+
+	if stats.FullLoadHours != 1.5 {
+		t.Errorf("Flex.Stats() FullLoadHours = %f, want 1.5",
+			stats.FullLoadHours)
+	}
+}
+
+// Asserts that the capacity of a participant without a TotalCapacity is its
+// peak load.
+func TestConsumerStats(t *testing.T) {
+	cons := Consumer{Profile: [8760]float64{1.0, 4.0}, TotalDemand: 1.0}
+	stats := cons.Stats()
+
+	if stats.Capacity != 4.0 {
+		t.Errorf("Consumer.Stats() Capacity = %f, want 4.0", stats.Capacity)
+	}
+
+	if stats.FullLoadHours != 1.25 {
+		t.Errorf("Consumer.Stats() FullLoadHours = %f, want 1.25",
+			stats.FullLoadHours)
+	}
+}
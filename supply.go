@@ -0,0 +1,112 @@
+package merit
+
+import (
+	"math"
+	"sort"
+)
+
+// SupplyStep is a step in the supply curve of a frame: a participant able to
+// produce Capacity at Price. Cumulative is the total capacity of this and every
+// cheaper step.
+type SupplyStep struct {
+	Participant Participant
+	Price       float64
+	Capacity    float64
+	Cumulative  float64
+}
+
+// SupplyCurve is the capacity available to meet demand in a frame, sorted by
+// price.
+type SupplyCurve []SupplyStep
+
+// SupplyCurveAt returns the supply curve of frame. Always-on producers are
+// included at a price of zero, flexibles with the energy they have available
+// at their discharging price, and dispatchables, imports and suppliers at their
+// cost. Storage offers the energy it held at the start of the frame, so that
+// the curve of a calculated order includes what the storage went on to produce.
+//
+// The curve may be built without calculating the order, in which case flexibles
+// which store energy will have nothing available.
+func (o *Order) SupplyCurveAt(frame int) SupplyCurve {
+	var curve SupplyCurve
+
+	for _, producer := range o.producers() {
+		curve = append(curve, SupplyStep{
+			Participant: producer,
+			Capacity:    producer.LoadAt(frame),
+		})
+	}
+
+	for _, flex := range o.Flexibles {
+		curve = append(curve, SupplyStep{
+			Participant: flex,
+			Price:       dischargingPrice(flex),
+			Capacity:    supplyAvailableAt(flex, frame),
+		})
+	}
+
+	offers := o.Dispatchables.offers()
+
+	for _, imp := range o.Imports {
+		offers = append(offers, imp.offerAt(frame))
+	}
+
+	for _, supplier := range o.Suppliers {
+		offers = append(offers, supplierOffersAt(supplier, frame)...)
+	}
+
+	for _, offer := range offers {
+		curve = append(curve, SupplyStep{
+			Participant: offer.producer,
+			Price:       offer.cost,
+			Capacity:    offer.capacity,
+		})
+	}
+
+	sort.SliceStable(curve, func(i, j int) bool {
+		return curve[i].Price < curve[j].Price
+	})
+
+	var cumulative float64
+
+	for i := range curve {
+		cumulative += curve[i].Capacity
+		curve[i].Cumulative = cumulative
+	}
+
+	return curve
+}
+
+// supplyAvailableAt returns the energy the flexible may produce in frame.
+// Storage offers what it held at the start of the frame, before any load
+// assigned to it in a calculation, and is read without resolving amounts it has
+// not yet computed, so that building the curve does not change a later
+// calculation.
+func supplyAvailableAt(flex Flexible, frame int) float64 {
+	if storage, ok := flex.(*Storage); ok {
+		return storage.availableFrom(storage.reserve.peek(frame) + storage.load[frame])
+	}
+
+	return flex.AvailableAt(frame)
+}
+
+// PriceFor returns the price of energy, and the participant which would set
+// it, were demand to be met from the supply curve. Steps with no capacity are
+// skipped. Returns positive infinity and nil when there is insufficient
+// capacity to meet demand.
+func (sc SupplyCurve) PriceFor(demand float64) (float64, Participant) {
+	for _, step := range sc {
+		if step.Capacity > 0 && step.Cumulative >= demand {
+			return step.Price, step.Participant
+		}
+	}
+
+	return math.Inf(1), nil
+}
+
+// PriceForDemand returns the price of energy in frame, and the participant
+// which would set it, were demand to be met from the supply curve of frame.
+// See SupplyCurve.PriceFor.
+func (o *Order) PriceForDemand(frame int, demand float64) (float64, Participant) {
+	return o.SupplyCurveAt(frame).PriceFor(demand)
+}
@@ -0,0 +1,140 @@
+package merit
+
+import (
+	"math"
+	"testing"
+)
+
+func supplyTestOrder() (Order, *AlwaysOn, *Dispatchable, *Dispatchable) {
+	ao := &AlwaysOn{Profile: [8760]float64{1.0}, TotalProduction: 1.0}
+	cheap := &Dispatchable{Cost: 1.0, Capacity: 2.0, Units: 1.0}
+	dear := &Dispatchable{
+		Cost:     5.0,
+		Capacity: 2.0,
+		Units:    1.0,
+		Blocks:   &BlockList{{Share: 0.5, Cost: 3.0}},
+	}
+
+	order := NewOrder()
+	order.AddAlwaysOn(ao)
+	order.AddDispatchable(dear)
+	order.AddDispatchable(cheap)
+
+	return order, ao, cheap, dear
+}
+
+func TestOrderSupplyCurveAt(t *testing.T) {
+	order, ao, cheap, dear := supplyTestOrder()
+	curve := order.SupplyCurveAt(0)
+
+	tests := []SupplyStep{
+		{ao, 0.0, 1.0, 1.0},
+		{cheap, 1.0, 2.0, 3.0},
+		{dear, 3.0, 1.0, 4.0},
+		{dear, 5.0, 1.0, 5.0},
+	}
+
+	if len(curve) != len(tests) {
+		t.Fatalf("Order.SupplyCurveAt(0) returned %d steps, want %d",
+			len(curve), len(tests))
+	}
+
+	for i, test := range tests {
+		if curve[i] != test {
+			t.Errorf("Order.SupplyCurveAt(0)[%d] = {Price: %f, Capacity: %f, "+
+				"Cumulative: %f}, want {Price: %f, Capacity: %f, "+
+				"Cumulative: %f}", i, curve[i].Price, curve[i].Capacity,
+				curve[i].Cumulative, test.Price, test.Capacity,
+				test.Cumulative)
+		}
+	}
+}
+
+func TestOrderPriceForDemand(t *testing.T) {
+	order, ao, cheap, dear := supplyTestOrder()
+
+	tests := []struct {
+		demand     float64
+		wantPrice  float64
+		wantSetter Participant
+	}{
+		{0.5, 0.0, ao},
+		{2.0, 1.0, cheap},
+		{3.5, 3.0, dear},
+		{5.0, 5.0, dear},
+		{6.0, math.Inf(1), nil},
+	}
+
+	for _, test := range tests {
+		price, setter := order.PriceForDemand(0, test.demand)
+
+		if price != test.wantPrice {
+			t.Errorf("Order.PriceForDemand(0, %f) price = %f, want %f",
+				test.demand, price, test.wantPrice)
+		}
+
+		if setter != test.wantSetter {
+			t.Errorf("Order.PriceForDemand(0, %f) has the wrong price setter",
+				test.demand)
+		}
+	}
+}
+
+// Asserts that building a supply curve before calculating the order does not
+// change the energy later stored.
+func TestOrderSupplyCurveAtBeforeCalculate(t *testing.T) {
+	newOrder := func() (Order, *Storage) {
+		ao := &AlwaysOn{Profile: [8760]float64{1.0, 1.0}, TotalProduction: 1.0}
+		storage := &Storage{
+			Flex:    Flex{Capacity: 1.0, Units: 1.0},
+			reserve: NewReserveWithoutDecay(5.0),
+		}
+
+		order := NewOrder()
+		order.AddConsumer(&Consumer{Profile: [8760]float64{0.0, 0.0, 1.0}, TotalDemand: 1.0})
+		order.AddAlwaysOn(ao)
+		order.AddStorage(storage)
+
+		return order, storage
+	}
+
+	want, wantStorage := newOrder()
+	Calculate(want)
+
+	order, storage := newOrder()
+	order.SupplyCurveAt(2)
+	Calculate(order)
+
+	if load := storage.LoadAt(2); load != wantStorage.LoadAt(2) {
+		t.Errorf("Storage.LoadAt(2) = %f, want %f", load, wantStorage.LoadAt(2))
+	}
+
+	if stored := storage.StoredAt(2); stored != wantStorage.StoredAt(2) {
+		t.Errorf("Storage.StoredAt(2) = %f, want %f", stored, wantStorage.StoredAt(2))
+	}
+}
+
+// Asserts that a calculated storage offers the energy it held at the start of
+// each frame, rather than what remained after it discharged.
+func TestOrderPriceForDemandAfterCalculate(t *testing.T) {
+	storage := &Storage{
+		Flex:    Flex{Capacity: 1.0, Units: 1.0},
+		reserve: NewReserveWithoutDecay(5.0),
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{0.0, 0.0, 1.0, 1.0}, TotalDemand: 1.0})
+	order.AddAlwaysOn(&AlwaysOn{Profile: [8760]float64{1.0, 1.0}, TotalProduction: 1.0})
+	order.AddStorage(storage)
+
+	Calculate(order)
+
+	// The storage is emptied in frame 3.
+	if stored := storage.StoredAt(3); stored != 0.0 {
+		t.Fatalf("Storage.StoredAt(3) = %f, want 0.0", stored)
+	}
+
+	if price, setter := order.PriceForDemand(3, 1.0); price != 0.0 || setter != storage {
+		t.Errorf("PriceForDemand(3, 1) = %f, %v, want 0.0 by the storage", price, setter)
+	}
+}
@@ -0,0 +1,289 @@
+package merit
+
+import (
+	"math"
+	"slices"
+)
+
+// tracker remembers the inputs of an order when it was last calculated, so that
+// Recalculate may find which frames are affected by changes since.
+type tracker struct {
+	calculated bool
+
+	consumers []Participant
+	demand    [][8760]float64
+	segments  [][]DemandSegment
+
+	alwaysOns  []Participant
+	production [][8760]float64
+
+	offers map[*Dispatchable][]offer
+}
+
+// TrackChanges causes the order to remember its inputs each time it is
+// calculated with Calculate, CalculateParallel or Recalculate, so that
+// Recalculate may calculate only the frames affected by later changes.
+func (o *Order) TrackChanges() {
+	o.tracker = &tracker{}
+}
+
+// snapshot records the inputs of the order after it has been calculated.
+func (t *tracker) snapshot(order Order) {
+	if t == nil {
+		return
+	}
+
+	t.consumers = order.demands()
+	t.demand = make([][8760]float64, len(t.consumers))
+
+	for i, consumer := range t.consumers {
+		t.demand[i] = loadCurve(consumer)
+	}
+
+	t.segments = make([][]DemandSegment, len(order.Consumers))
+
+	for i, consumer := range order.Consumers {
+		t.segments[i] = slices.Clone(consumer.Segments)
+	}
+
+	t.alwaysOns = order.producers()
+	t.production = make([][8760]float64, len(t.alwaysOns))
+
+	for i, producer := range t.alwaysOns {
+		t.production[i] = loadCurve(producer)
+	}
+
+	t.offers = make(map[*Dispatchable][]offer, len(order.Dispatchables))
+
+	for _, producer := range order.Dispatchables {
+		t.offers[producer] = producer.offers()
+	}
+
+	t.calculated = true
+}
+
+// forget discards the snapshot, after the order has been calculated in a way
+// which Recalculate does not follow.
+func (t *tracker) forget() {
+	if t != nil {
+		t.calculated = false
+	}
+}
+
+// affected returns the frames of the order affected by changes to its inputs
+// since the snapshot was taken. all is true when every frame is affected.
+func (t *tracker) affected(order Order) (frames []bool, all bool) {
+	if !t.calculated ||
+		!sameParticipants(t.consumers, order.demands()) ||
+		!sameParticipants(t.alwaysOns, order.producers()) ||
+		len(t.offers) != len(order.Dispatchables) {
+		return nil, true
+	}
+
+	// Segments apply to the demand of every frame.
+	for i, consumer := range order.Consumers {
+		if !slices.Equal(t.segments[i], consumer.Segments) {
+			return nil, true
+		}
+	}
+
+	frames = make([]bool, 8760)
+
+	for i, consumer := range order.demands() {
+		markChanged(frames, &t.demand[i], consumer)
+	}
+
+	for i, producer := range order.producers() {
+		markChanged(frames, &t.production[i], producer)
+	}
+
+	for _, producer := range order.Dispatchables {
+		previous, ok := t.offers[producer]
+
+		if !ok {
+			return nil, true
+		}
+
+		current := producer.offers()
+
+		if sameOffers(previous, current) {
+			continue
+		}
+
+		// A dispatchable affects frames in which it was running, and those in
+		// which the price was at or above its cheapest offer before or after
+		// the change. Frames with unmet demand, or no price setter, are
+		// affected by any change in capacity.
+		cost := math.Min(cheapest(previous), cheapest(current))
+
+		for frame := range frames {
+			setter, price := pricedAt(order, frame)
+
+			if producer.LoadAt(frame) > 0 || setter == nil ||
+				order.UnmetAt(frame) > 0 || price >= cost {
+				frames[frame] = true
+			}
+		}
+	}
+
+	return frames, false
+}
+
+// Recalculate calculates again the frames of the order affected by changes to
+// its consumers, always-on producers and dispatchables since it was last
+// calculated, and returns how many frames were calculated. Every frame is
+// calculated when the order does not TrackChanges, has not been calculated
+// with Calculate, CalculateParallel or Recalculate, participants have been
+// added or removed, or the Segments of a consumer have changed.
+//
+// Changes to the demand of consumers and production of always-ons, including
+// those of other types, are detected through LoadAt; changes to dispatchables
+// through their cost, capacity and blocks. Changes to flexibles, suppliers,
+// imports, exports, stages and ProRata are not detected, nor are changes to
+// the bids of a Bidder; use Calculate after changing those.
+//
+// Storage carries energy from one frame to the next, so an order with storage,
+// or another kind of flexible which may hold state, is calculated from the
+// first affected frame to the end of the year.
+func Recalculate(order Order) (int, error) {
+	frames, all := []bool(nil), true
+
+	if order.tracker != nil {
+		frames, all = order.tracker.affected(order)
+	}
+
+	if all {
+		// Flexibles keep the results of the earlier calculation, which
+		// Calculate would otherwise build upon.
+		order.forget(0, 8760)
+
+		return 8760, Calculate(order)
+	}
+
+	if order.stateful() {
+		for frame, affected := range frames {
+			if !affected {
+				continue
+			}
+
+			if err := CalculateWindow(order, Window{Start: frame, End: 8760}); err != nil {
+				return 8760 - frame, err
+			}
+
+			order.tracker.snapshot(order)
+
+			return 8760 - frame, nil
+		}
+
+		return 0, nil
+	}
+
+	var count int
+
+	prepare(&order)
+
+	for frame, affected := range frames {
+		if !affected {
+			continue
+		}
+
+		order.forget(frame, frame+1)
+
+		if err := calculateFrame(frame, order); err != nil {
+			order.tracker.forget()
+			return count, err
+		}
+
+		count++
+	}
+
+	order.tracker.snapshot(order)
+
+	return count, nil
+}
+
+// stateful returns whether any flexible in the order may carry state from one
+// frame to the next.
+func (o *Order) stateful() bool {
+	for _, flex := range o.Flexibles {
+		switch flex.(type) {
+		case *Flex, *Sink:
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// pricedAt returns the participant which set the price in frame, and the price.
+// Orders created without NewOrder record neither, and have no price setter.
+func pricedAt(order Order, frame int) (Participant, float64) {
+	if order.PriceSetters == nil || order.Prices == nil {
+		return nil, 0
+	}
+
+	return order.PriceSetters[frame], order.Prices[frame]
+}
+
+func loadCurve(p Participant) [8760]float64 {
+	var curve [8760]float64
+
+	for frame := range curve {
+		curve[frame] = p.LoadAt(frame)
+	}
+
+	return curve
+}
+
+// markChanged marks each frame in which the load of the participant differs
+// from the previous load.
+func markChanged(frames []bool, previous *[8760]float64, p Participant) {
+	for frame := range frames {
+		if p.LoadAt(frame) != previous[frame] {
+			frames[frame] = true
+		}
+	}
+}
+
+func sameParticipants(previous, current []Participant) bool {
+	if len(previous) != len(current) {
+		return false
+	}
+
+	for i := range previous {
+		if previous[i] != current[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameOffers(previous, current []offer) bool {
+	if len(previous) != len(current) {
+		return false
+	}
+
+	for i := range previous {
+		if previous[i] != current[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cheapest returns the lowest cost of the offers.
+func cheapest(offers []offer) float64 {
+	cost := math.Inf(1)
+
+	for _, o := range offers {
+		if o.capacity > 0 && o.cost < cost {
+			cost = o.cost
+		}
+	}
+
+	return cost
+}
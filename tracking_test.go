@@ -0,0 +1,263 @@
+package merit
+
+import "testing"
+
+type trackedOrder struct {
+	order   Order
+	cons    *Consumer
+	coal    *Dispatchable
+	peaker  *Dispatchable
+	storage *Storage
+}
+
+// newTrackedOrder returns an order whose peaker is needed only in the first
+// frame of each day.
+func newTrackedOrder(withStorage bool) trackedOrder {
+	o := trackedOrder{
+		cons:   &Consumer{TotalDemand: 1.0},
+		coal:   &Dispatchable{Cost: 1.0, Capacity: 5.0, Units: 1.0},
+		peaker: &Dispatchable{Cost: 10.0, Capacity: 10.0, Units: 1.0},
+	}
+
+	ao := &AlwaysOn{TotalProduction: 1.0}
+
+	for frame := 0; frame < 8760; frame++ {
+		o.cons.Profile[frame] = 4.0
+		ao.Profile[frame] = float64(frame%2) * 2.0
+
+		if frame%24 == 0 {
+			o.cons.Profile[frame] = 8.0
+		}
+	}
+
+	o.order = NewOrder()
+	o.order.AddConsumer(o.cons)
+	o.order.AddAlwaysOn(ao)
+	o.order.AddDispatchable(o.coal)
+	o.order.AddDispatchable(o.peaker)
+
+	if withStorage {
+		o.storage = &Storage{
+			Flex:    Flex{Capacity: 1.0, Units: 1.0},
+			reserve: NewReserveWithoutDecay(5.0),
+		}
+
+		o.order.AddStorage(o.storage)
+	}
+
+	return o
+}
+
+// assertSameResults asserts that the tracked order has the same results as an
+// order calculated in full.
+func assertSameResults(t *testing.T, got, want trackedOrder) {
+	for frame := 0; frame < 8760; frame++ {
+		if got.coal.LoadAt(frame) != want.coal.LoadAt(frame) ||
+			got.peaker.LoadAt(frame) != want.peaker.LoadAt(frame) ||
+			got.order.PriceAt(frame) != want.order.PriceAt(frame) {
+			t.Fatalf("Recalculate frame %d differs from Calculate", frame)
+		}
+
+		if got.storage != nil && got.storage.StoredAt(frame) != want.storage.StoredAt(frame) {
+			t.Fatalf("Recalculate StoredAt(%d) differs from Calculate", frame)
+		}
+	}
+}
+
+func TestRecalculateChangedDemand(t *testing.T) {
+	tracked := newTrackedOrder(false)
+	tracked.order.TrackChanges()
+	Calculate(tracked.order)
+
+	want := newTrackedOrder(false)
+
+	for _, o := range []trackedOrder{tracked, want} {
+		o.cons.Profile[5] = 2.0
+		o.cons.Profile[6] = 7.0
+	}
+
+	Calculate(want.order)
+
+	if count, err := Recalculate(tracked.order); count != 2 || err != nil {
+		t.Errorf("Recalculate() = %d, %v, want 2, nil", count, err)
+	}
+
+	assertSameResults(t, tracked, want)
+}
+
+// Asserts that changing a dispatchable recalculates only the frames in which
+// it was running or at or above the margin.
+func TestRecalculateChangedDispatchable(t *testing.T) {
+	tracked := newTrackedOrder(false)
+	tracked.order.TrackChanges()
+	Calculate(tracked.order)
+
+	want := newTrackedOrder(false)
+	want.peaker.Capacity = 2.0
+	Calculate(want.order)
+
+	tracked.peaker.Capacity = 2.0
+
+	if count, err := Recalculate(tracked.order); count != 365 || err != nil {
+		t.Errorf("Recalculate() = %d, %v, want 365, nil", count, err)
+	}
+
+	assertSameResults(t, tracked, want)
+
+	if count, _ := Recalculate(tracked.order); count != 0 {
+		t.Errorf("Recalculate() without changes = %d, want 0", count)
+	}
+}
+
+// Asserts that an order with storage is calculated from the first affected
+// frame.
+func TestRecalculateWithStorage(t *testing.T) {
+	tracked := newTrackedOrder(true)
+	tracked.order.TrackChanges()
+	Calculate(tracked.order)
+
+	want := newTrackedOrder(true)
+
+	for _, o := range []trackedOrder{tracked, want} {
+		o.cons.Profile[100] = 1.0
+	}
+
+	Calculate(want.order)
+
+	if count, err := Recalculate(tracked.order); count != 8660 || err != nil {
+		t.Errorf("Recalculate() = %d, %v, want 8660, nil", count, err)
+	}
+
+	assertSameResults(t, tracked, want)
+}
+
+func TestRecalculateEveryFrame(t *testing.T) {
+	untracked := newTrackedOrder(false)
+	Calculate(untracked.order)
+
+	if count, _ := Recalculate(untracked.order); count != 8760 {
+		t.Errorf("Recalculate() without TrackChanges = %d, want 8760", count)
+	}
+
+	tracked := newTrackedOrder(false)
+	tracked.order.TrackChanges()
+	Calculate(tracked.order)
+
+	tracked.order.AddDispatchable(&Dispatchable{Cost: 5.0, Capacity: 1.0, Units: 1.0})
+
+	if count, _ := Recalculate(tracked.order); count != 8760 {
+		t.Errorf("Recalculate() after adding a participant = %d, want 8760", count)
+	}
+}
+
+// Asserts that an order created without NewOrder, which records no prices, has
+// every frame affected by a change to a dispatchable.
+func TestAffectedWithoutPrices(t *testing.T) {
+	disp := &Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0}
+
+	order := Order{}
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0})
+	order.AddDispatchable(disp)
+
+	tracker := &tracker{}
+	tracker.snapshot(order)
+
+	disp.Capacity = 2.0
+
+	frames, all := tracker.affected(order)
+
+	if all {
+		t.Fatalf("affected() = all, want each frame marked")
+	}
+
+	for frame, affected := range frames {
+		if !affected {
+			t.Fatalf("affected() left frame %d unaffected", frame)
+		}
+	}
+}
+
+// Asserts that frames calculated with Frames are calculated again by
+// Recalculate, even when the inputs match those of the last Calculate.
+func TestRecalculateAfterFrames(t *testing.T) {
+	tracked := newTrackedOrder(false)
+	tracked.order.TrackChanges()
+	Calculate(tracked.order)
+
+	tracked.cons.Profile[5] = 20.0
+
+	for range Frames(tracked.order) {
+	}
+
+	tracked.cons.Profile[5] = 4.0
+
+	want := newTrackedOrder(false)
+	Calculate(want.order)
+
+	if count, err := Recalculate(tracked.order); count != 8760 || err != nil {
+		t.Errorf("Recalculate() after Frames = %d, %v, want 8760, nil", count, err)
+	}
+
+	assertSameResults(t, tracked, want)
+}
+
+// Asserts that a flex is calculated afresh when participants are added.
+func TestRecalculateAddedParticipantWithFlex(t *testing.T) {
+	build := func() (Order, *Flex) {
+		flex := &Flex{Capacity: 1.0, Units: 1.0}
+
+		order := NewOrder()
+		order.AddConsumer(&Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0})
+		order.AddAlwaysOn(&AlwaysOn{Profile: [8760]float64{3.5}, TotalProduction: 1.0})
+		order.AddFlex(flex)
+
+		return order, flex
+	}
+
+	tracked, flex := build()
+	tracked.TrackChanges()
+	Calculate(tracked)
+
+	tracked.AddConsumer(&Consumer{TotalDemand: 1.0})
+
+	if count, err := Recalculate(tracked); count != 8760 || err != nil {
+		t.Errorf("Recalculate() = %d, %v, want 8760, nil", count, err)
+	}
+
+	want, wantFlex := build()
+	want.AddConsumer(&Consumer{TotalDemand: 1.0})
+	Calculate(want)
+
+	if load := flex.LoadAt(0); load != wantFlex.LoadAt(0) {
+		t.Errorf("Recalculate assigned flex load %f, want %f", load, wantFlex.LoadAt(0))
+	}
+
+	if curtailed := tracked.CurtailedAt(0); curtailed != want.CurtailedAt(0) {
+		t.Errorf("CurtailedAt(0) = %f, want %f", curtailed, want.CurtailedAt(0))
+	}
+}
+
+// Asserts that changing the segments of a consumer recalculates every frame.
+func TestRecalculateChangedSegments(t *testing.T) {
+	tracked := newTrackedOrder(false)
+	tracked.order.TrackChanges()
+	Calculate(tracked.order)
+
+	want := newTrackedOrder(false)
+
+	for _, o := range []trackedOrder{tracked, want} {
+		o.cons.Segments = []DemandSegment{{Share: 0.5, MaxPrice: 5.0}}
+	}
+
+	Calculate(want.order)
+
+	if count, err := Recalculate(tracked.order); count != 8760 || err != nil {
+		t.Errorf("Recalculate() = %d, %v, want 8760, nil", count, err)
+	}
+
+	assertSameResults(t, tracked, want)
+
+	if shed := tracked.cons.ShedAt(0); shed != want.cons.ShedAt(0) || shed == 0 {
+		t.Errorf("ShedAt(0) = %f, want %f", shed, want.cons.ShedAt(0))
+	}
+}
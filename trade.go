@@ -0,0 +1,127 @@
+package merit
+
+import "sort"
+
+// Import is a source of energy from a neighbouring market. It behaves like a
+// Dispatchable, except that its cost and capacity vary in each frame: Prices
+// contains the price of energy in each frame, and Availability the share of
+// the total capacity which may be imported.
+type Import struct {
+	Key          string
+	Capacity     float64
+	Units        float64
+	Prices       [8760]float64
+	Availability [8760]float64
+	load         [8760]float64
+}
+
+// TotalCapacity returns the total amount of energy which may be imported in
+// each hour in kWh.
+func (i *Import) TotalCapacity() float64 {
+	return i.Capacity * i.Units
+}
+
+// LoadAt returns the energy imported in frame.
+func (i *Import) LoadAt(frame int) float64 {
+	return i.load[frame]
+}
+
+// resetAt removes the energy imported in frame.
+func (i *Import) resetAt(frame int) {
+	i.load[frame] = 0
+}
+
+// addLoadAt increases the energy imported in frame.
+func (i *Import) addLoadAt(frame int, amount float64) {
+	i.load[frame] += amount
+}
+
+// offerAt returns the capacity offered by the import in frame.
+func (i *Import) offerAt(frame int) offer {
+	return offer{
+		producer: i,
+		cost:     i.Prices[frame],
+		capacity: i.Availability[frame] * i.TotalCapacity(),
+	}
+}
+
+// Export is a neighbouring market to which energy may be sold. In each frame it
+// absorbs energy for as long as the price in the merit order is below the
+// foreign price in Prices, up to the share of its total capacity given by
+// Availability.
+type Export struct {
+	Key          string
+	Capacity     float64
+	Units        float64
+	Prices       [8760]float64
+	Availability [8760]float64
+	shed         [8760]float64
+}
+
+// TotalCapacity returns the total amount of energy which may be exported in
+// each hour in kWh.
+func (e *Export) TotalCapacity() float64 {
+	return e.Capacity * e.Units
+}
+
+// LoadAt returns the energy exported in frame.
+func (e *Export) LoadAt(frame int) float64 {
+	return e.demandAt(frame) - e.shed[frame]
+}
+
+// demandAt returns the amount of energy the export would absorb in frame, were
+// the domestic price low enough.
+func (e *Export) demandAt(frame int) float64 {
+	return e.Availability[frame] * e.TotalCapacity()
+}
+
+// resetAt forgets the energy which was not exported in frame.
+func (e *Export) resetAt(frame int) {
+	e.shed[frame] = 0
+}
+
+// addShedAt records that energy offered to the export in frame was not
+// exported because it was too expensive.
+func (e *Export) addShedAt(frame int, amount float64) {
+	e.shed[frame] += amount
+}
+
+// bidAt returns the export as price-sensitive demand in frame.
+func (e *Export) bidAt(frame int) bid {
+	return bid{owner: e, price: e.Prices[frame], amount: e.demandAt(frame)}
+}
+
+// offersAt returns the offers of the dispatchables, imports and suppliers in
+// frame, sorted by cost.
+func (o *Order) offersAt(frame int) offerList {
+	if len(o.Imports) == 0 && len(o.Suppliers) == 0 {
+		return o.offers
+	}
+
+	offers := make(offerList, len(o.offers), len(o.offers)+len(o.Imports))
+	copy(offers, o.offers)
+
+	for _, imp := range o.Imports {
+		offers = append(offers, imp.offerAt(frame))
+	}
+
+	for _, supplier := range o.Suppliers {
+		offers = append(offers, supplierOffersAt(supplier, frame)...)
+	}
+
+	sort.Stable(offers)
+
+	return offers
+}
+
+// exportsAt returns the amount of energy all exports would absorb in frame,
+// were the domestic price low enough.
+func (o *Order) exportsAt(frame int) float64 {
+	var sum float64
+
+	for _, export := range o.Exports {
+		sum += export.demandAt(frame)
+	}
+
+	return sum
+}
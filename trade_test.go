@@ -0,0 +1,150 @@
+package merit
+
+import "testing"
+
+func TestImportOfferAt(t *testing.T) {
+	imp := Import{
+		Capacity:     2.0,
+		Units:        2.0,
+		Prices:       [8760]float64{1.0, 5.0},
+		Availability: [8760]float64{0.5, 1.0},
+	}
+
+	tests := []struct {
+		frame          int
+		cost, capacity float64
+	}{
+		{0, 1.0, 2.0},
+		{1, 5.0, 4.0},
+		{2, 0.0, 0.0},
+	}
+
+	for _, test := range tests {
+		offer := imp.offerAt(test.frame)
+
+		if offer.producer != &imp {
+			t.Errorf("Import.offerAt(%d) has the wrong producer", test.frame)
+		}
+
+		if offer.cost != test.cost || offer.capacity != test.capacity {
+			t.Errorf("Import.offerAt(%d) = {Cost: %f, Capacity: %f}, "+
+				"want {Cost: %f, Capacity: %f}", test.frame, offer.cost,
+				offer.capacity, test.cost, test.capacity)
+		}
+	}
+}
+
+func TestExportLoadAt(t *testing.T) {
+	export := Export{
+		Capacity:     2.0,
+		Units:        1.0,
+		Availability: [8760]float64{1.0, 0.5},
+	}
+
+	export.addShedAt(0, 0.5)
+
+	tests := []struct {
+		frame int
+		want  float64
+	}{
+		{0, 1.5},
+		{1, 1.0},
+		{2, 0.0},
+	}
+
+	for _, test := range tests {
+		if load := export.LoadAt(test.frame); load != test.want {
+			t.Errorf("Export.LoadAt(%d) = %f, want %f",
+				test.frame, load, test.want)
+		}
+	}
+}
+
+func TestCalculateImportsAndExports(t *testing.T) {
+	disp := Dispatchable{Cost: 3.0, Capacity: 10.0, Units: 1.0}
+	cons := Consumer{Profile: [8760]float64{3.0, 3.0}, TotalDemand: 1.0}
+
+	imp := Import{
+		Key:          "import",
+		Capacity:     4.0,
+		Units:        1.0,
+		Prices:       [8760]float64{1.0, 5.0},
+		Availability: [8760]float64{0.5, 1.0},
+	}
+
+	export := Export{
+		Key:          "export",
+		Capacity:     2.0,
+		Units:        1.0,
+		Prices:       [8760]float64{2.0, 4.0},
+		Availability: [8760]float64{1.0, 1.0},
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&cons)
+	order.AddDispatchable(&disp)
+	order.AddImport(&imp)
+	order.AddExport(&export)
+
+	Calculate(order)
+
+	tests := []struct {
+		frame      int
+		wantDisp   float64
+		wantImport float64
+		wantExport float64
+		wantPrice  float64
+	}{
+		{0, 1.0, 2.0, 0.0, 3.0}, // Import is cheap; exporting is not worth it.
+		{1, 5.0, 0.0, 2.0, 3.0}, // Import is dear; exporting is worth it.
+	}
+
+	for _, test := range tests {
+		if load := disp.LoadAt(test.frame); load != test.wantDisp {
+			t.Errorf("Calculate assigned dispatchable load %d = %f, want %f",
+				test.frame, load, test.wantDisp)
+		}
+
+		if load := imp.LoadAt(test.frame); load != test.wantImport {
+			t.Errorf("Calculate assigned import load %d = %f, want %f",
+				test.frame, load, test.wantImport)
+		}
+
+		if load := export.LoadAt(test.frame); load != test.wantExport {
+			t.Errorf("Calculate assigned export load %d = %f, want %f",
+				test.frame, load, test.wantExport)
+		}
+
+		if price := order.PriceAt(test.frame); price != test.wantPrice {
+			t.Errorf("Calculate assigned price in frame %d = %f, want %f",
+				test.frame, price, test.wantPrice)
+		}
+	}
+}
+
+// Asserts that an import which meets the last of the demand is recorded as the
+// price setter.
+func TestCalculateImportSetsPrice(t *testing.T) {
+	imp := Import{
+		Capacity:     4.0,
+		Units:        1.0,
+		Prices:       [8760]float64{2.0},
+		Availability: [8760]float64{1.0},
+	}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{3.0}, TotalDemand: 1.0})
+	order.AddDispatchable(&Dispatchable{Cost: 1.0, Capacity: 1.0, Units: 1.0})
+	order.AddImport(&imp)
+
+	Calculate(order)
+
+	if setter := order.PriceSetters[0]; setter != &imp {
+		t.Errorf("Calculate assigned price setter %p in frame 0, want the import %p",
+			setter, &imp)
+	}
+
+	if load := imp.LoadAt(0); load != 2.0 {
+		t.Errorf("Import.LoadAt(0) = %f, want 2.0", load)
+	}
+}
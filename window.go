@@ -0,0 +1,102 @@
+package merit
+
+import "fmt"
+
+// Window is a range of frames to be calculated, from Start up to but not
+// including End.
+//
+// Stored is the energy held by each storage at the start of the window, after
+// any decay in its first frame. A storage which is not listed starts with the
+// energy it held at the end of the frame before the window, or empty if the
+// window starts in the first frame.
+type Window struct {
+	Start, End int
+	Stored     map[*Storage]float64
+}
+
+// CalculateWindow calculates only the frames in the window. Frames outside the
+// window keep the results of any earlier calculation; the energy stored after
+// the window may therefore no longer follow from the energy stored within it.
+//
+// An error is returned when the window is outside the year, when Stored
+// contains a storage which is not part of the order, or when an Observer of
+// the order stops the calculation.
+func CalculateWindow(order Order, window Window) error {
+	if window.Start < 0 || window.End > 8760 || window.Start >= window.End {
+		return fmt.Errorf(
+			"CalculateWindow: Invalid window of frames %d to %d",
+			window.Start, window.End)
+	}
+
+	var storages []*Storage
+
+	for _, flex := range order.Flexibles {
+		if storage, ok := flex.(*Storage); ok {
+			storages = append(storages, storage)
+		}
+	}
+
+	for storage := range window.Stored {
+		if !containsStorage(storages, storage) {
+			return fmt.Errorf(
+				"CalculateWindow: Storage %q is not part of the order",
+				storage.Key)
+		}
+	}
+
+	order.forget(window.Start, window.End)
+
+	for _, storage := range storages {
+		if amount, ok := window.Stored[storage]; ok {
+			defer storage.seedAt(window.Start, amount)()
+		}
+	}
+
+	// Recalculate cannot know which frames of a window were calculated.
+	order.tracker.forget()
+
+	prepare(&order)
+
+	return calculateFrameBatch(window.Start, window.End, order)
+}
+
+// forgetter is implemented by flexibles which must forget the results of
+// earlier calculations before frames are calculated again.
+type forgetter interface {
+	forget(start, end int)
+}
+
+// forget discards the results of the flexibles, and of other participants
+// which implement Forgetter, in frames start to end (exclusive).
+func (o *Order) forget(start, end int) {
+	for _, flex := range o.Flexibles {
+		switch f := flex.(type) {
+		case forgetter:
+			f.forget(start, end)
+		case Forgetter:
+			f.Forget(start, end)
+		}
+	}
+
+	forgetAll(o.Demands, start, end)
+	forgetAll(o.Suppliers, start, end)
+}
+
+// forgetAll calls Forget on each participant which implements Forgetter.
+func forgetAll[P Participant](list []P, start, end int) {
+	for _, p := range list {
+		if f, ok := Participant(p).(Forgetter); ok {
+			f.Forget(start, end)
+		}
+	}
+}
+
+func containsStorage(storages []*Storage, storage *Storage) bool {
+	for _, s := range storages {
+		if s == storage {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package merit
+
+import "testing"
+
+// windowOrder returns an order whose storage charges from always-on production
+// every other frame, and discharges in between.
+func windowOrder() (Order, *Consumer, *Storage, *Dispatchable) {
+	cons := &Consumer{TotalDemand: 1.0}
+	ao := &AlwaysOn{TotalProduction: 1.0}
+
+	for frame := 0; frame < 8760; frame++ {
+		cons.Profile[frame] = 2.0
+		ao.Profile[frame] = float64(frame%2) * 3.0
+	}
+
+	storage := &Storage{
+		Flex:    Flex{Key: "battery", Capacity: 1.0, Units: 1.0},
+		reserve: NewReserveWithoutDecay(10.0),
+	}
+
+	disp := &Dispatchable{Cost: 1.0, Capacity: 10.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(cons)
+	order.AddAlwaysOn(ao)
+	order.AddStorage(storage)
+	order.AddDispatchable(disp)
+
+	return order, cons, storage, disp
+}
+
+// Asserts that a window starting from the storage level of a full calculation
+// has the same results as that calculation.
+func TestCalculateWindowMatchesYear(t *testing.T) {
+	year, _, yearStorage, yearDisp := windowOrder()
+	Calculate(year)
+
+	order, _, storage, disp := windowOrder()
+
+	err := CalculateWindow(order, Window{
+		Start:  100,
+		End:    200,
+		Stored: map[*Storage]float64{storage: yearStorage.StoredAt(99)},
+	})
+
+	if err != nil {
+		t.Fatalf("CalculateWindow returned an error: %s", err)
+	}
+
+	for frame := 100; frame < 200; frame++ {
+		if storage.LoadAt(frame) != yearStorage.LoadAt(frame) ||
+			storage.StoredAt(frame) != yearStorage.StoredAt(frame) ||
+			disp.LoadAt(frame) != yearDisp.LoadAt(frame) {
+			t.Fatalf("CalculateWindow frame %d differs from Calculate", frame)
+		}
+	}
+
+	if load := disp.LoadAt(200); load != 0.0 {
+		t.Errorf("LoadAt(200) = %f, want frames after the window uncalculated", load)
+	}
+}
+
+// Asserts that a window may be calculated again after its inputs change.
+func TestCalculateWindowAgain(t *testing.T) {
+	order, cons, storage, disp := windowOrder()
+	Calculate(order)
+
+	for frame := 10; frame < 20; frame++ {
+		cons.Profile[frame] = 0.0
+	}
+
+	if err := CalculateWindow(order, Window{Start: 10, End: 20}); err != nil {
+		t.Fatalf("CalculateWindow returned an error: %s", err)
+	}
+
+	if load := disp.LoadAt(10); load != 0.0 {
+		t.Errorf("LoadAt(10) = %f, want 0.0", load)
+	}
+
+	// The storage charges 1.0 in each odd frame, and never discharges.
+	if stored, want := storage.StoredAt(19), storage.StoredAt(9)+5.0; stored != want {
+		t.Errorf("StoredAt(19) = %f, want %f", stored, want)
+	}
+}
+
+func TestCalculateWindowStartingStorage(t *testing.T) {
+	order, _, storage, _ := windowOrder()
+
+	err := CalculateWindow(order, Window{
+		Start:  0,
+		End:    2,
+		Stored: map[*Storage]float64{storage: 5.0},
+	})
+
+	if err != nil {
+		t.Fatalf("CalculateWindow returned an error: %s", err)
+	}
+
+	// Discharges 1.0 in frame 0, and charges 1.0 in frame 1.
+	if stored := storage.StoredAt(0); stored != 4.0 {
+		t.Errorf("StoredAt(0) = %f, want 4.0", stored)
+	}
+
+	if stored := storage.StoredAt(1); stored != 5.0 {
+		t.Errorf("StoredAt(1) = %f, want 5.0", stored)
+	}
+}
+
+// Asserts that the frame before the window keeps its results when the window
+// has a starting storage level.
+func TestCalculateWindowKeepsFrameBefore(t *testing.T) {
+	order, _, storage, _ := windowOrder()
+	Calculate(order)
+
+	stored, load := storage.StoredAt(99), storage.LoadAt(99)
+	value := storage.value[99]
+
+	err := CalculateWindow(order, Window{
+		Start:  100,
+		End:    200,
+		Stored: map[*Storage]float64{storage: 8.0},
+	})
+
+	if err != nil {
+		t.Fatalf("CalculateWindow returned an error: %s", err)
+	}
+
+	if storage.StoredAt(99) != stored || storage.LoadAt(99) != load ||
+		storage.value[99] != value {
+		t.Errorf("CalculateWindow changed frame 99, before the window")
+	}
+
+	// Discharges 1.0 in frame 100.
+	if stored := storage.StoredAt(100); stored != 7.0 {
+		t.Errorf("StoredAt(100) = %f, want 7.0", stored)
+	}
+}
+
+// Asserts that decay is applied to the starting storage level in the same way
+// wherever the window starts.
+func TestCalculateWindowStartingStorageWithDecay(t *testing.T) {
+	for _, start := range []int{0, 100} {
+		order, _, storage, _ := windowOrder()
+		storage.reserve = NewReserve(10.0, func(frame int, stored float64) float64 {
+			return stored * 0.5
+		})
+
+		err := CalculateWindow(order, Window{
+			Start:  start,
+			End:    start + 2,
+			Stored: map[*Storage]float64{storage: 5.0},
+		})
+
+		if err != nil {
+			t.Fatalf("CalculateWindow returned an error: %s", err)
+		}
+
+		// Discharges 1.0 in the first frame, before any more decay.
+		if stored := storage.StoredAt(start); stored != 4.0 {
+			t.Errorf("StoredAt(%d) = %f, want 4.0", start, stored)
+		}
+	}
+}
+
+// Asserts that a flex absorbs the same excess each time a window is calculated.
+func TestCalculateWindowWithFlex(t *testing.T) {
+	ao := &AlwaysOn{Profile: [8760]float64{3.0}, TotalProduction: 1.0}
+	flex := &Flex{Capacity: 1.0, Units: 1.0}
+
+	order := NewOrder()
+	order.AddConsumer(&Consumer{Profile: [8760]float64{1.0}, TotalDemand: 1.0})
+	order.AddAlwaysOn(ao)
+	order.AddFlex(flex)
+
+	for i := 0; i < 2; i++ {
+		if err := CalculateWindow(order, Window{Start: 0, End: 1}); err != nil {
+			t.Fatalf("CalculateWindow returned an error: %s", err)
+		}
+
+		if load := flex.LoadAt(0); load != -1.0 {
+			t.Errorf("LoadAt(0) after %d windows = %f, want -1.0", i+1, load)
+		}
+	}
+}
+
+func TestCalculateWindowErrors(t *testing.T) {
+	order, _, _, _ := windowOrder()
+
+	windows := []Window{
+		{Start: -1, End: 10},
+		{Start: 10, End: 8761},
+		{Start: 10, End: 10},
+		{Start: 0, End: 10, Stored: map[*Storage]float64{{}: 1.0}},
+	}
+
+	for _, window := range windows {
+		if err := CalculateWindow(order, window); err == nil {
+			t.Errorf("CalculateWindow(%d, %d) returned no error", window.Start, window.End)
+		}
+	}
+}